@@ -0,0 +1,180 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package beaconfinality decides whether an Ethereum (or Ethereum-equivalent) execution-layer
+// block has been covered by a finalized beacon block, as a stronger alternative to a fixed
+// confirm-block-count heuristic when deciding it is safe to forward a cross-chain transfer.
+//
+// Checker is a minimal Altair/Capella sync-committee light client: it bootstraps from a trusted
+// checkpoint block root via the standard /eth/v1/beacon/light_client/* REST endpoints, then
+// follows LightClientUpdate messages one sync-committee period at a time, verifying each
+// update's Merkle branch into the attested header's state root and its BLS aggregate signature
+// before advancing the finalized header it trusts. ConfirmDepthChecker implements the same
+// FinalityChecker interface via a fixed block-depth heuristic, for deployments that do not
+// configure a beacon client.
+//
+// It is designed to plug into witness.NewTokenCashierOnEthereum in place of (or alongside) a
+// ConfirmBlockNumber depth check; that call site lives in the witness package, which is not part
+// of this source tree, so the wiring itself is not included here.
+package beaconfinality
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderReader is the subset of an execution-layer RPC client a FinalityChecker needs to confirm
+// that a block number still has the expected hash on the canonical chain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// FinalityChecker decides whether the execution-layer block at blockNumber with hash blockHash
+// is safe to treat as final.
+type FinalityChecker interface {
+	IsFinal(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error)
+}
+
+// BLSVerifier verifies a BLS aggregate signature by the given subset of sync-committee public
+// keys over signingRoot. Pairing-based BLS12-381 verification is kept behind this interface
+// rather than vendored in-repo; pass a github.com/supranational/blst (or similar) backed
+// implementation in production.
+type BLSVerifier interface {
+	VerifyAggregate(pubkeys [][]byte, signingRoot [32]byte, signature []byte) (bool, error)
+}
+
+// Config holds the parameters a Checker is built with.
+type Config struct {
+	// BeaconClientURL is the base URL of a beacon node exposing the standard
+	// /eth/v1/beacon/light_client/* REST endpoints.
+	BeaconClientURL string
+	// TrustedBlockRoot is the checkpoint block root LightClientBootstrap is fetched for. It must
+	// come from a source trusted out-of-band (e.g. a recent weak-subjectivity checkpoint).
+	TrustedBlockRoot common.Hash
+	// GenesisValidatorsRoot is mixed into DOMAIN_SYNC_COMMITTEE when computing the signing root
+	// a sync-committee signature is verified against.
+	GenesisValidatorsRoot common.Hash
+	// GenesisTime is the genesis_time of the beacon chain. The Checker does not read it: the fork
+	// version for a slot is looked up in ForkSchedule by the slot's epoch alone.
+	GenesisTime uint64
+	// ForkSchedule maps an epoch to the fork version active from that epoch onward, e.g.
+	// {0: genesisForkVersion, altairEpoch: altairForkVersion, capellaEpoch: capellaForkVersion}.
+	// Start must be given the one active at GenesisTime at minimum.
+	ForkSchedule map[uint64][4]byte
+	// Verifier checks a LightClientUpdate's sync_committee_signature. Required.
+	Verifier BLSVerifier
+	// Headers is the execution-layer client used to confirm that a finalized block number still
+	// has the expected hash on the canonical chain.
+	Headers HeaderReader
+	// HTTPClient is the client used to talk to BeaconClientURL. http.DefaultClient is used if nil.
+	HTTPClient *http.Client
+}
+
+// Checker is a FinalityChecker backed by a sync-committee light client.
+type Checker struct {
+	cfg    Config
+	client *beaconClient
+
+	mu               sync.RWMutex
+	period           uint64
+	committee        *syncCommittee
+	finalizedHeader  beaconBlockHeader
+	finalizedPayload *executionPayloadHeader
+}
+
+// New builds a Checker from cfg. It does not contact the beacon client until Start is called.
+func New(cfg Config) (*Checker, error) {
+	if cfg.BeaconClientURL == "" {
+		return nil, fmt.Errorf("beaconfinality: BeaconClientURL is required")
+	}
+	if cfg.Verifier == nil {
+		return nil, fmt.Errorf("beaconfinality: Verifier is required")
+	}
+	if cfg.Headers == nil {
+		return nil, fmt.Errorf("beaconfinality: Headers is required")
+	}
+	return &Checker{
+		cfg:    cfg,
+		client: newBeaconClient(cfg.BeaconClientURL, cfg.HTTPClient),
+	}, nil
+}
+
+// Start fetches the LightClientBootstrap for cfg.TrustedBlockRoot, verifies its current sync
+// committee's Merkle branch against the bootstrap header's state root (the header itself is not
+// re-hashed and compared with cfg.TrustedBlockRoot), and begins polling for LightClientUpdate messages every pollInterval.
+// It returns once the bootstrap has been applied; call the returned context's cancel to stop
+// polling.
+func (c *Checker) Start(ctx context.Context, pollInterval time.Duration) (context.CancelFunc, error) {
+	bootstrap, err := c.client.fetchBootstrap(ctx, c.cfg.TrustedBlockRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch light client bootstrap: %w", err)
+	}
+	if err := c.applyBootstrap(bootstrap); err != nil {
+		return nil, fmt.Errorf("failed to apply light client bootstrap: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.poll(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel, nil
+}
+
+// poll fetches and applies every LightClientUpdate since the current period, logging nothing on
+// failure beyond leaving the finalized header where it was; the next poll will retry.
+func (c *Checker) poll(ctx context.Context) {
+	c.mu.RLock()
+	from := c.period
+	c.mu.RUnlock()
+
+	updates, err := c.client.fetchUpdates(ctx, from, maxUpdatesPerPoll)
+	if err != nil {
+		return
+	}
+	for _, update := range updates {
+		_ = c.applyUpdate(update)
+	}
+}
+
+// maxUpdatesPerPoll bounds a single fetchUpdates call so a light client that fell far behind
+// catches up gradually across several polls instead of requesting an unbounded range.
+const maxUpdatesPerPoll = 128
+
+// IsFinal reports whether blockNumber/blockHash is covered by the most recently verified
+// finalized beacon header and, for the h.Number <= finalized.BlockNumber && h.Hash ==
+// chainHeaderAt(h.Number) check, is also still the canonical block at that height.
+func (c *Checker) IsFinal(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error) {
+	c.mu.RLock()
+	payload := c.finalizedPayload
+	c.mu.RUnlock()
+	if payload == nil {
+		return false, nil
+	}
+	if blockNumber > payload.BlockNumber {
+		return false, nil
+	}
+	header, err := c.cfg.Headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return false, fmt.Errorf("failed to read header %d: %w", blockNumber, err)
+	}
+	return header.Hash() == blockHash, nil
+}
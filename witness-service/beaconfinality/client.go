@@ -0,0 +1,302 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package beaconfinality
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// beaconClient fetches LightClientBootstrap/LightClientUpdate payloads from the standard
+// /eth/v1/beacon/light_client/* REST endpoints exposed by a beacon node.
+type beaconClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// newBeaconClient builds a beaconClient against baseURL, defaulting to http.DefaultClient when
+// httpClient is nil.
+func newBeaconClient(baseURL string, httpClient *http.Client) *beaconClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &beaconClient{baseURL: strings.TrimSuffix(baseURL, "/"), http: httpClient}
+}
+
+// fetchBootstrap calls /eth/v1/beacon/light_client/bootstrap/{block_root}.
+func (b *beaconClient) fetchBootstrap(ctx context.Context, blockRoot common.Hash) (lightClientBootstrap, error) {
+	var envelope struct {
+		Data bootstrapJSON `json:"data"`
+	}
+	url := fmt.Sprintf("%s/eth/v1/beacon/light_client/bootstrap/%s", b.baseURL, blockRoot.Hex())
+	if err := b.get(ctx, url, &envelope); err != nil {
+		return lightClientBootstrap{}, err
+	}
+	return envelope.Data.toBootstrap()
+}
+
+// fetchUpdates calls /eth/v1/beacon/light_client/updates?start_period=&count=.
+func (b *beaconClient) fetchUpdates(ctx context.Context, startPeriod uint64, count int) ([]lightClientUpdate, error) {
+	var envelope []struct {
+		Data updateJSON `json:"data"`
+	}
+	url := fmt.Sprintf("%s/eth/v1/beacon/light_client/updates?start_period=%d&count=%d", b.baseURL, startPeriod, count)
+	if err := b.get(ctx, url, &envelope); err != nil {
+		return nil, err
+	}
+	updates := make([]lightClientUpdate, 0, len(envelope))
+	for _, item := range envelope {
+		update, err := item.Data.toUpdate()
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+func (b *beaconClient) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// The beacon REST API renders every integer as a decimal string and every root/pubkey/signature
+// as a "0x"-prefixed hex string; the *JSON types below mirror that wire format, and their to*
+// methods convert them into the internal SSZ types used by lightclient.go. hash_tree_root is
+// computed by those internal types, not here.
+
+type beaconBlockHeaderJSON struct {
+	Slot          string `json:"slot"`
+	ProposerIndex string `json:"proposer_index"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+func (h beaconBlockHeaderJSON) toHeader() (beaconBlockHeader, error) {
+	slot, err := decodeUint64(h.Slot)
+	if err != nil {
+		return beaconBlockHeader{}, fmt.Errorf("invalid slot %q: %w", h.Slot, err)
+	}
+	proposerIndex, err := decodeUint64(h.ProposerIndex)
+	if err != nil {
+		return beaconBlockHeader{}, fmt.Errorf("invalid proposer_index %q: %w", h.ProposerIndex, err)
+	}
+	parentRoot, err := decodeHash(h.ParentRoot)
+	if err != nil {
+		return beaconBlockHeader{}, fmt.Errorf("invalid parent_root: %w", err)
+	}
+	stateRoot, err := decodeHash(h.StateRoot)
+	if err != nil {
+		return beaconBlockHeader{}, fmt.Errorf("invalid state_root: %w", err)
+	}
+	bodyRoot, err := decodeHash(h.BodyRoot)
+	if err != nil {
+		return beaconBlockHeader{}, fmt.Errorf("invalid body_root: %w", err)
+	}
+	return beaconBlockHeader{
+		Slot:          slot,
+		ProposerIndex: proposerIndex,
+		ParentRoot:    parentRoot,
+		StateRoot:     stateRoot,
+		BodyRoot:      bodyRoot,
+	}, nil
+}
+
+type syncCommitteeJSON struct {
+	Pubkeys   []string `json:"pubkeys"`
+	Aggregate string   `json:"aggregate_pubkey"`
+}
+
+func (s syncCommitteeJSON) toCommittee() (syncCommittee, error) {
+	pubkeys := make([][]byte, len(s.Pubkeys))
+	for i, pk := range s.Pubkeys {
+		b, err := decodeHex(pk)
+		if err != nil {
+			return syncCommittee{}, fmt.Errorf("invalid pubkey %d: %w", i, err)
+		}
+		pubkeys[i] = b
+	}
+	aggregate, err := decodeHex(s.Aggregate)
+	if err != nil {
+		return syncCommittee{}, fmt.Errorf("invalid aggregate_pubkey: %w", err)
+	}
+	return syncCommittee{Pubkeys: pubkeys, Aggregate: aggregate}, nil
+}
+
+type syncAggregateJSON struct {
+	Bits      string `json:"sync_committee_bits"`
+	Signature string `json:"sync_committee_signature"`
+}
+
+func (a syncAggregateJSON) toAggregate() (syncAggregate, error) {
+	bits, err := decodeHex(a.Bits)
+	if err != nil {
+		return syncAggregate{}, fmt.Errorf("invalid sync_committee_bits: %w", err)
+	}
+	sig, err := decodeHex(a.Signature)
+	if err != nil {
+		return syncAggregate{}, fmt.Errorf("invalid sync_committee_signature: %w", err)
+	}
+	return syncAggregate{Bits: bits, Signature: sig}, nil
+}
+
+type executionPayloadHeaderJSON struct {
+	BlockNumber string `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+}
+
+func (e executionPayloadHeaderJSON) toPayload() (executionPayloadHeader, error) {
+	number, err := decodeUint64(e.BlockNumber)
+	if err != nil {
+		return executionPayloadHeader{}, fmt.Errorf("invalid block_number %q: %w", e.BlockNumber, err)
+	}
+	hash, err := decodeHash(e.BlockHash)
+	if err != nil {
+		return executionPayloadHeader{}, fmt.Errorf("invalid block_hash: %w", err)
+	}
+	return executionPayloadHeader{BlockNumber: number, BlockHash: hash}, nil
+}
+
+type bootstrapJSON struct {
+	Header                     beaconBlockHeaderJSON `json:"header"`
+	CurrentSyncCommittee       syncCommitteeJSON     `json:"current_sync_committee"`
+	CurrentSyncCommitteeBranch []string              `json:"current_sync_committee_branch"`
+}
+
+func (j bootstrapJSON) toBootstrap() (lightClientBootstrap, error) {
+	header, err := j.Header.toHeader()
+	if err != nil {
+		return lightClientBootstrap{}, err
+	}
+	committee, err := j.CurrentSyncCommittee.toCommittee()
+	if err != nil {
+		return lightClientBootstrap{}, err
+	}
+	branch, err := decodeBranch(j.CurrentSyncCommitteeBranch)
+	if err != nil {
+		return lightClientBootstrap{}, fmt.Errorf("invalid current_sync_committee_branch: %w", err)
+	}
+	return lightClientBootstrap{
+		Header:                     header,
+		CurrentSyncCommittee:       committee,
+		CurrentSyncCommitteeBranch: branch,
+	}, nil
+}
+
+type updateJSON struct {
+	AttestedHeader          beaconBlockHeaderJSON       `json:"attested_header"`
+	NextSyncCommittee       *syncCommitteeJSON          `json:"next_sync_committee,omitempty"`
+	NextSyncCommitteeBranch []string                    `json:"next_sync_committee_branch,omitempty"`
+	FinalizedHeader         beaconBlockHeaderJSON       `json:"finalized_header"`
+	FinalityBranch          []string                    `json:"finality_branch"`
+	FinalizedExecution      *executionPayloadHeaderJSON `json:"finalized_execution_payload,omitempty"`
+	SyncAggregate           syncAggregateJSON           `json:"sync_aggregate"`
+	SignatureSlot           string                      `json:"signature_slot"`
+}
+
+func (j updateJSON) toUpdate() (lightClientUpdate, error) {
+	attested, err := j.AttestedHeader.toHeader()
+	if err != nil {
+		return lightClientUpdate{}, fmt.Errorf("invalid attested_header: %w", err)
+	}
+	finalized, err := j.FinalizedHeader.toHeader()
+	if err != nil {
+		return lightClientUpdate{}, fmt.Errorf("invalid finalized_header: %w", err)
+	}
+	finalityBranch, err := decodeBranch(j.FinalityBranch)
+	if err != nil {
+		return lightClientUpdate{}, fmt.Errorf("invalid finality_branch: %w", err)
+	}
+	aggregate, err := j.SyncAggregate.toAggregate()
+	if err != nil {
+		return lightClientUpdate{}, err
+	}
+	signatureSlot, err := decodeUint64(j.SignatureSlot)
+	if err != nil {
+		return lightClientUpdate{}, fmt.Errorf("invalid signature_slot %q: %w", j.SignatureSlot, err)
+	}
+	update := lightClientUpdate{
+		AttestedHeader:  attested,
+		FinalizedHeader: finalized,
+		FinalityBranch:  finalityBranch,
+		SyncAggregate:   aggregate,
+		SignatureSlot:   signatureSlot,
+	}
+	if j.NextSyncCommittee != nil {
+		next, err := j.NextSyncCommittee.toCommittee()
+		if err != nil {
+			return lightClientUpdate{}, err
+		}
+		nextBranch, err := decodeBranch(j.NextSyncCommitteeBranch)
+		if err != nil {
+			return lightClientUpdate{}, fmt.Errorf("invalid next_sync_committee_branch: %w", err)
+		}
+		update.NextSyncCommittee = &next
+		update.NextSyncCommitteeBranch = nextBranch
+	}
+	if j.FinalizedExecution != nil {
+		payload, err := j.FinalizedExecution.toPayload()
+		if err != nil {
+			return lightClientUpdate{}, err
+		}
+		update.FinalizedExecution = payload
+	}
+	return update, nil
+}
+
+func decodeUint64(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func decodeHash(s string) (common.Hash, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("expected %d bytes, got %d", common.HashLength, len(b))
+	}
+	return common.BytesToHash(b), nil
+}
+
+func decodeBranch(nodes []string) ([][32]byte, error) {
+	branch := make([][32]byte, len(nodes))
+	for i, n := range nodes {
+		h, err := decodeHash(n)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		}
+		branch[i] = [32]byte(h)
+	}
+	return branch, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package beaconfinality
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ConfirmDepthChecker is a FinalityChecker that treats a block as final once chain head has
+// advanced confirmations blocks past it, the heuristic Checker replaces when a beacon client is
+// configured. It exists so callers can depend on the FinalityChecker interface alone and fall
+// back to it when BeaconClientURL is unset.
+type ConfirmDepthChecker struct {
+	confirmations uint64
+	headers       HeaderReader
+}
+
+// NewConfirmDepthChecker builds a ConfirmDepthChecker requiring confirmations blocks of depth,
+// reading chain head through headers.
+func NewConfirmDepthChecker(confirmations uint64, headers HeaderReader) *ConfirmDepthChecker {
+	return &ConfirmDepthChecker{confirmations: confirmations, headers: headers}
+}
+
+// IsFinal reports whether blockNumber/blockHash is still canonical and at least confirmations
+// blocks behind the current chain head.
+func (c *ConfirmDepthChecker) IsFinal(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error) {
+	head, err := c.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to read chain head: %w", err)
+	}
+	if head.Number.Uint64() < blockNumber+c.confirmations {
+		return false, nil
+	}
+	header, err := c.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return false, fmt.Errorf("failed to read header %d: %w", blockNumber, err)
+	}
+	return header.Hash() == blockHash, nil
+}
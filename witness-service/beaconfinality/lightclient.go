@@ -0,0 +1,299 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package beaconfinality
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// slotsPerEpoch and epochsPerSyncCommitteePeriod are fixed beacon-chain parameters (mainnet
+// preset); they do not vary across forks the way gindices below do.
+const (
+	slotsPerEpoch                = 32
+	epochsPerSyncCommitteePeriod = 256
+)
+
+// Generalized indices of the fields a LightClientUpdate proves membership of, per the Altair
+// light client sync protocol (github.com/ethereum/consensus-specs, specs/altair/light-client/
+// sync-protocol.md). They are fixed constants; the Checker has no way to override them for a fork
+// whose beacon state places these fields at different indices.
+const (
+	currentSyncCommitteeGIndex      = 54
+	currentSyncCommitteeGIndexDepth = 5
+	nextSyncCommitteeGIndex         = 55
+	nextSyncCommitteeGIndexDepth    = 5
+	finalizedRootGIndex             = 105
+	finalizedRootGIndexDepth        = 6
+)
+
+// beaconBlockHeader is the SSZ BeaconBlockHeader container: slot, proposer_index, parent_root,
+// state_root, body_root.
+type beaconBlockHeader struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    common.Hash
+	StateRoot     common.Hash
+	BodyRoot      common.Hash
+}
+
+// hashTreeRoot computes the SSZ hash_tree_root of h, i.e. merkleize(slot, proposer_index,
+// parent_root, state_root, body_root) with the two uint64 fields encoded little-endian and
+// zero-padded on the right to 32 bytes.
+func (h beaconBlockHeader) hashTreeRoot() common.Hash {
+	return merkleize([][32]byte{
+		uint64SSZLeaf(h.Slot),
+		uint64SSZLeaf(h.ProposerIndex),
+		[32]byte(h.ParentRoot),
+		[32]byte(h.StateRoot),
+		[32]byte(h.BodyRoot),
+	})
+}
+
+// syncCommittee is the SSZ SyncCommittee container: 512 BLS12-381 pubkeys plus their aggregate.
+type syncCommittee struct {
+	Pubkeys   [][]byte // 48 bytes each
+	Aggregate []byte   // 48 bytes
+}
+
+// hashTreeRoot computes the SSZ hash_tree_root of the SyncCommittee container: merkleize(pubkeys,
+// aggregate_pubkey), each a Vector/Bytes48 per the consensus-specs SSZ encoding.
+func (c syncCommittee) hashTreeRoot() common.Hash {
+	pubkeyRoots := make([][32]byte, len(c.Pubkeys))
+	for i, pk := range c.Pubkeys {
+		pubkeyRoots[i] = [32]byte(bytes48HashTreeRoot(pk))
+	}
+	pubkeysRoot := merkleize(pubkeyRoots)
+	aggregateRoot := bytes48HashTreeRoot(c.Aggregate)
+	return merkleize([][32]byte{[32]byte(pubkeysRoot), [32]byte(aggregateRoot)})
+}
+
+// bytes48HashTreeRoot computes the SSZ hash_tree_root of a fixed 48-byte basic-type value (e.g. a
+// BLS12-381 pubkey): the value packed into 32-byte chunks (2, zero-padded) and merkleized.
+func bytes48HashTreeRoot(b []byte) common.Hash {
+	var chunks [2][32]byte
+	copy(chunks[0][:], b)
+	if len(b) > 32 {
+		copy(chunks[1][:], b[32:])
+	}
+	return merkleize(chunks[:])
+}
+
+// syncAggregate is the SSZ SyncAggregate container attached to a LightClientUpdate: a bitvector
+// of which of the 512 current-period committee members participated, plus their BLS aggregate
+// signature over the attested header's signing root.
+type syncAggregate struct {
+	Bits      []byte // 64-byte bitvector, bit i set if Pubkeys[i] participated
+	Signature []byte // 96-byte BLS12-381 signature
+}
+
+// participants returns the subset of committee.Pubkeys that signed, per agg.Bits.
+func (agg syncAggregate) participants(committee syncCommittee) [][]byte {
+	var pubkeys [][]byte
+	for i, pk := range committee.Pubkeys {
+		byteIdx, bitIdx := i/8, uint(i%8)
+		if byteIdx >= len(agg.Bits) {
+			break
+		}
+		if agg.Bits[byteIdx]&(1<<bitIdx) != 0 {
+			pubkeys = append(pubkeys, pk)
+		}
+	}
+	return pubkeys
+}
+
+// executionPayloadHeader carries just the two fields IsFinal needs from a finalized beacon
+// block's execution payload.
+type executionPayloadHeader struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+}
+
+// lightClientBootstrap is the /eth/v1/beacon/light_client/bootstrap/{block_root} response: the
+// checkpoint header plus the current sync committee and its Merkle proof into that header's
+// state root.
+type lightClientBootstrap struct {
+	Header                     beaconBlockHeader
+	CurrentSyncCommittee       syncCommittee
+	CurrentSyncCommitteeBranch [][32]byte
+}
+
+// lightClientUpdate is one entry of the /eth/v1/beacon/light_client/updates response: an
+// attested header signed by the current sync committee, optionally the next period's sync
+// committee (with its own Merkle proof), and a finalized header (with its own Merkle proof into
+// the attested header's state root) plus that finalized block's execution payload header.
+type lightClientUpdate struct {
+	AttestedHeader          beaconBlockHeader
+	NextSyncCommittee       *syncCommittee
+	NextSyncCommitteeBranch [][32]byte
+	FinalizedHeader         beaconBlockHeader
+	FinalityBranch          [][32]byte
+	FinalizedExecution      executionPayloadHeader
+	SyncAggregate           syncAggregate
+	SignatureSlot           uint64
+}
+
+// applyBootstrap seeds the Checker's period and current sync committee from bootstrap, after
+// verifying the committee's Merkle branch into the bootstrap header's own state root. The
+// bootstrap header itself is trusted (it was fetched for the caller-supplied TrustedBlockRoot).
+func (c *Checker) applyBootstrap(bootstrap lightClientBootstrap) error {
+	leaf := bootstrap.CurrentSyncCommittee.hashTreeRoot()
+	if !verifyMerkleBranch(leaf, bootstrap.CurrentSyncCommitteeBranch, currentSyncCommitteeGIndex, currentSyncCommitteeGIndexDepth, bootstrap.Header.StateRoot) {
+		return fmt.Errorf("bootstrap current sync committee failed Merkle verification")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.period = bootstrap.Header.Slot / slotsPerEpoch / epochsPerSyncCommitteePeriod
+	c.committee = &bootstrap.CurrentSyncCommittee
+	c.finalizedHeader = bootstrap.Header
+	return nil
+}
+
+// applyUpdate verifies update's Merkle branches and BLS aggregate signature against the
+// currently trusted sync committee, then advances the Checker's finalized header/payload and,
+// crossing a period boundary, its current sync committee. A failed or stale update is rejected
+// without mutating any state.
+func (c *Checker) applyUpdate(update lightClientUpdate) error {
+	c.mu.RLock()
+	committee := c.committee
+	period := c.period
+	finalizedSlot := c.finalizedHeader.Slot
+	c.mu.RUnlock()
+	if committee == nil {
+		return fmt.Errorf("no trusted sync committee yet; call Start first")
+	}
+	if update.FinalizedHeader.Slot <= finalizedSlot {
+		return fmt.Errorf("stale update: finalized slot %d is not newer than %d", update.FinalizedHeader.Slot, finalizedSlot)
+	}
+
+	finalizedLeaf := update.FinalizedHeader.hashTreeRoot()
+	if !verifyMerkleBranch(finalizedLeaf, update.FinalityBranch, finalizedRootGIndex, finalizedRootGIndexDepth, update.AttestedHeader.StateRoot) {
+		return fmt.Errorf("finalized header failed Merkle verification against attested state root")
+	}
+	if update.NextSyncCommittee != nil {
+		nextLeaf := update.NextSyncCommittee.hashTreeRoot()
+		if !verifyMerkleBranch(nextLeaf, update.NextSyncCommitteeBranch, nextSyncCommitteeGIndex, nextSyncCommitteeGIndexDepth, update.AttestedHeader.StateRoot) {
+			return fmt.Errorf("next sync committee failed Merkle verification against attested state root")
+		}
+	}
+
+	participants := update.SyncAggregate.participants(*committee)
+	if len(participants)*3 < len(committee.Pubkeys)*2 {
+		return fmt.Errorf("sync aggregate has only %d/%d participants, need >= 2/3", len(participants), len(committee.Pubkeys))
+	}
+	domain := computeDomain(domainSyncCommittee, c.forkVersionForSlot(update.SignatureSlot), c.cfg.GenesisValidatorsRoot)
+	signingRoot := computeSigningRoot(update.AttestedHeader.hashTreeRoot(), domain)
+	ok, err := c.cfg.Verifier.VerifyAggregate(participants, signingRoot, update.SyncAggregate.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to verify sync committee signature: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("sync committee signature verification failed")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finalizedHeader = update.FinalizedHeader
+	c.finalizedPayload = &update.FinalizedExecution
+	newPeriod := update.AttestedHeader.Slot / slotsPerEpoch / epochsPerSyncCommitteePeriod
+	if newPeriod > period && update.NextSyncCommittee != nil {
+		c.committee = update.NextSyncCommittee
+		c.period = newPeriod
+	}
+	return nil
+}
+
+// domainSyncCommittee is DOMAIN_SYNC_COMMITTEE from the Altair consensus spec.
+var domainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
+
+// forkVersionForSlot returns the fork version active at slot's epoch, per cfg.ForkSchedule.
+func (c *Checker) forkVersionForSlot(slot uint64) [4]byte {
+	epoch := slot / slotsPerEpoch
+	var best [4]byte
+	bestEpoch := uint64(0)
+	found := false
+	for e, v := range c.cfg.ForkSchedule {
+		if e <= epoch && (!found || e >= bestEpoch) {
+			bestEpoch, best, found = e, v, true
+		}
+	}
+	return best
+}
+
+// computeForkDataRoot implements compute_fork_data_root from the consensus spec: the SSZ
+// hash_tree_root of ForkData(current_version, genesis_validators_root).
+func computeForkDataRoot(currentVersion [4]byte, genesisValidatorsRoot common.Hash) common.Hash {
+	var versionLeaf [32]byte
+	copy(versionLeaf[:4], currentVersion[:])
+	return sha256.Sum256(append(versionLeaf[:], genesisValidatorsRoot[:]...))
+}
+
+// computeDomain implements compute_domain from the consensus spec: a domain type tagged with the
+// first 28 bytes of the active fork's ForkData root.
+func computeDomain(domainType [4]byte, forkVersion [4]byte, genesisValidatorsRoot common.Hash) [32]byte {
+	forkDataRoot := computeForkDataRoot(forkVersion, genesisValidatorsRoot)
+	var domain [32]byte
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// computeSigningRoot implements compute_signing_root from the consensus spec: sha256(objectRoot
+// || domain).
+func computeSigningRoot(objectRoot common.Hash, domain [32]byte) common.Hash {
+	return sha256.Sum256(append(objectRoot[:], domain[:]...))
+}
+
+// uint64SSZLeaf encodes v little-endian in the first 8 bytes of a 32-byte SSZ basic-type leaf,
+// zero-padded on the right.
+func uint64SSZLeaf(v uint64) [32]byte {
+	var leaf [32]byte
+	binary.LittleEndian.PutUint64(leaf[:8], v)
+	return leaf
+}
+
+// merkleize computes the SSZ hash_tree_root of a fixed-length vector of leaves, zero-padding to
+// the next power of two.
+func merkleize(leaves [][32]byte) common.Hash {
+	size := 1
+	for size < len(leaves) {
+		size <<= 1
+	}
+	nodes := make([][32]byte, size)
+	copy(nodes, leaves)
+	for size > 1 {
+		next := make([][32]byte, size/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(append([]byte{}, nodes[2*i][:]...), nodes[2*i+1][:]...))
+		}
+		nodes = next
+		size /= 2
+	}
+	return common.Hash(nodes[0])
+}
+
+// verifyMerkleBranch checks that leaf, combined with branch along generalized index gIndex (at
+// the given depth), reconstructs root.
+func verifyMerkleBranch(leaf [32]byte, branch [][32]byte, gIndex uint64, depth int, root common.Hash) bool {
+	if len(branch) != depth {
+		return false
+	}
+	computed := leaf
+	index := gIndex
+	for _, sibling := range branch {
+		if index%2 == 1 {
+			computed = sha256.Sum256(append(append([]byte{}, sibling[:]...), computed[:]...))
+		} else {
+			computed = sha256.Sum256(append(append([]byte{}, computed[:]...), sibling[:]...))
+		}
+		index /= 2
+	}
+	return common.Hash(computed) == root
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package beaconfinality
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestVerifyMerkleBranch builds a 4-leaf tree by hand (independently of merkleize) and checks
+// that verifyMerkleBranch accepts the real proof for one leaf and rejects a tampered leaf, a
+// short branch, and a branch with swapped sibling order.
+func TestVerifyMerkleBranch(t *testing.T) {
+	var l0, l1, l2, l3 [32]byte
+	l0[0], l1[0], l2[0], l3[0] = 1, 2, 3, 4
+	n0 := sha256.Sum256(append(append([]byte{}, l0[:]...), l1[:]...))
+	n1 := sha256.Sum256(append(append([]byte{}, l2[:]...), l3[:]...))
+	root := common.Hash(sha256.Sum256(append(append([]byte{}, n0[:]...), n1[:]...)))
+
+	if got := merkleize([][32]byte{l0, l1, l2, l3}); got != root {
+		t.Fatalf("merkleize disagrees with a hand-computed root: got %x want %x", got, root)
+	}
+
+	// l1 is leaf index 1 of 4 (depth 2), so its generalized index is 2^2+1 = 5 and its branch is
+	// [l0 (its sibling), n1 (the sibling subtree)].
+	branch := [][32]byte{l0, n1}
+	if !verifyMerkleBranch(l1, branch, 5, 2, root) {
+		t.Fatalf("expected the real branch for l1 to verify")
+	}
+
+	var tamperedLeaf [32]byte
+	tamperedLeaf[0] = 0xff
+	if verifyMerkleBranch(tamperedLeaf, branch, 5, 2, root) {
+		t.Fatalf("expected a tampered leaf to fail verification")
+	}
+
+	if verifyMerkleBranch(l1, [][32]byte{l0}, 5, 2, root) {
+		t.Fatalf("expected a branch shorter than depth to fail verification")
+	}
+
+	if verifyMerkleBranch(l1, [][32]byte{n1, l0}, 5, 2, root) {
+		t.Fatalf("expected a branch with swapped sibling order to fail verification")
+	}
+}
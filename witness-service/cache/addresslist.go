@@ -0,0 +1,253 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package cache maintains a materialised local view of an AddressList contract's active-address
+// set, so hot signature-verification loops can do an O(1) map lookup instead of an RPC per
+// membership check.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+)
+
+// pageSize is the limit passed to GetActiveItems while paging the initial snapshot.
+const pageSize = 100
+
+// changeBuffer is the number of buffered Change deltas a Subscribe channel can hold. Once it is
+// full, further deltas for that channel are dropped rather than blocking the cache's update loop.
+const changeBuffer = 64
+
+// ChangeKind describes whether a Change added or removed an address from the active set.
+type ChangeKind int
+
+const (
+	// Added means the address became active.
+	Added ChangeKind = iota
+	// Removed means the address was deactivated.
+	Removed
+)
+
+// Change is a single membership delta emitted on a Subscribe channel.
+type Change struct {
+	Kind    ChangeKind
+	Address common.Address
+}
+
+// AddressListCache is a materialised, continuously-updated view of an AddressList contract's
+// active-address set.
+type AddressListCache struct {
+	caller   *contract.AddressListCaller
+	filterer *contract.AddressListFilterer
+
+	// fallbackPollInterval polls NumOfActive as a backstop when the chain does not deliver logs
+	// reliably (e.g. a light node without full log support).
+	fallbackPollInterval time.Duration
+
+	mu       sync.RWMutex
+	active   map[common.Address]struct{}
+	numKnown *big.Int
+
+	subMu sync.Mutex
+	subs  []chan<- Change
+
+	cancel context.CancelFunc
+}
+
+// New builds an AddressListCache bound to the AddressList deployed at address, and performs the
+// initial snapshot load by paging GetActiveItems.
+func New(ctx context.Context, address common.Address, backend bind.ContractBackend, fallbackPollInterval time.Duration) (*AddressListCache, error) {
+	caller, err := contract.NewAddressListCaller(address, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind AddressList caller: %w", err)
+	}
+	filterer, err := contract.NewAddressListFilterer(address, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind AddressList filterer: %w", err)
+	}
+	c := &AddressListCache{
+		caller:               caller,
+		filterer:             filterer,
+		fallbackPollInterval: fallbackPollInterval,
+		active:               make(map[common.Address]struct{}),
+	}
+	if err := c.loadSnapshot(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadSnapshot populates the active set from scratch by paging GetActiveItems.
+func (c *AddressListCache) loadSnapshot(ctx context.Context) error {
+	opts := &bind.CallOpts{Context: ctx}
+	active := make(map[common.Address]struct{})
+	offset := big.NewInt(0)
+	for {
+		page, err := c.caller.GetActiveItems(opts, offset, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to page GetActiveItems: %w", err)
+		}
+		for _, item := range page.Items {
+			active[item] = struct{}{}
+		}
+		if len(page.Items) < pageSize {
+			break
+		}
+		offset = new(big.Int).Add(offset, big.NewInt(pageSize))
+	}
+
+	numActive, err := c.caller.NumOfActive(opts)
+	if err != nil {
+		return fmt.Errorf("failed to read numOfActive: %w", err)
+	}
+
+	c.mu.Lock()
+	c.active = active
+	c.numKnown = numActive
+	c.mu.Unlock()
+	return nil
+}
+
+// Start subscribes to ItemAdded/ItemDeactivated logs and begins applying them to the active set,
+// plus a fallback poll of NumOfActive in case the chain does not deliver logs reliably. It
+// returns once the subscriptions are established; call Stop (or cancel ctx) to tear the cache
+// down.
+func (c *AddressListCache) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	added := make(chan *contract.AddressListItemAdded)
+	addedSub, err := c.filterer.WatchItemAdded(&bind.WatchOpts{Context: ctx}, added, nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to watch ItemAdded: %w", err)
+	}
+	removed := make(chan *contract.AddressListItemDeactivated)
+	removedSub, err := c.filterer.WatchItemDeactivated(&bind.WatchOpts{Context: ctx}, removed, nil)
+	if err != nil {
+		addedSub.Unsubscribe()
+		cancel()
+		return fmt.Errorf("failed to watch ItemDeactivated: %w", err)
+	}
+
+	go func() {
+		defer addedSub.Unsubscribe()
+		defer removedSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-added:
+				c.apply(Change{Kind: Added, Address: ev.Item})
+			case ev := <-removed:
+				c.apply(Change{Kind: Removed, Address: ev.Item})
+			case <-addedSub.Err():
+				return
+			case <-removedSub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if c.fallbackPollInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(c.fallbackPollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					c.reconcile(ctx)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop tears down the subscriptions and fallback poll started by Start.
+func (c *AddressListCache) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// apply updates the active set for a single Change and fans it out to subscribers.
+func (c *AddressListCache) apply(change Change) {
+	c.mu.Lock()
+	switch change.Kind {
+	case Added:
+		c.active[change.Address] = struct{}{}
+	case Removed:
+		delete(c.active, change.Address)
+	}
+	c.mu.Unlock()
+
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for _, sub := range c.subs {
+		select {
+		case sub <- change:
+		default:
+			// a slow subscriber should not stall membership updates for everyone else
+		}
+	}
+}
+
+// reconcile re-reads NumOfActive and, if it disagrees with the cached snapshot size, reloads the
+// full snapshot. This is the fallback path for chains that do not deliver logs reliably.
+func (c *AddressListCache) reconcile(ctx context.Context) {
+	numActive, err := c.caller.NumOfActive(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	stale := c.numKnown == nil || numActive.Cmp(c.numKnown) != 0
+	c.mu.RUnlock()
+	if stale {
+		_ = c.loadSnapshot(ctx)
+	}
+}
+
+// Contains reports whether addr is currently active, in O(1).
+func (c *AddressListCache) Contains(addr common.Address) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.active[addr]
+	return ok
+}
+
+// Snapshot returns a copy of the currently active address set.
+func (c *AddressListCache) Snapshot() []common.Address {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]common.Address, 0, len(c.active))
+	for addr := range c.active {
+		out = append(out, addr)
+	}
+	return out
+}
+
+// Subscribe creates a channel, buffered to changeBuffer, registers it, and returns it; it then
+// receives each subsequent Change. It does not take a caller-supplied channel. A Change that
+// arrives while the channel's buffer is full is dropped for this subscriber, not delivered later.
+// The channel is never closed by the cache.
+func (c *AddressListCache) Subscribe() <-chan Change {
+	ch := make(chan Change, changeBuffer)
+	c.subMu.Lock()
+	c.subs = append(c.subs, ch)
+	c.subMu.Unlock()
+	return ch
+}
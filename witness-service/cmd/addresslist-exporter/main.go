@@ -0,0 +1,111 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/iotexproject/ioTube/witness-service/exporter"
+)
+
+var (
+	rpcURL           = flag.String("rpc", "", "RPC URL of the chain the AddressList contracts are deployed on")
+	addressesFlag    = flag.String("addresses", "", "comma-separated list of name=0xAddress pairs to export metrics for")
+	listenAddr       = flag.String("listen", ":9091", "address to serve /metrics on")
+	headPollInterval = flag.Duration("head-poll-interval", 15*time.Second, "how often to poll the chain head to compute watcher lag")
+	confirmations    = flag.Uint64("confirmations", 12, "blocks an OwnershipTransferred event must age before it is reflected in the owner gauge/counter")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "-rpc <url> -addresses <name=0xAddress,...> -listen <addr>")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *rpcURL == "" || *addressesFlag == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := ethclient.Dial(*rpcURL)
+	if err != nil {
+		log.Fatalf("failed to dial %s: %v\n", *rpcURL, err)
+	}
+
+	lists, err := parseLists(*addressesFlag, client)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	collector, err := exporter.NewCollector(reg, lists, *confirmations)
+	if err != nil {
+		log.Fatalf("failed to create collector: %v\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := collector.Start(ctx, *headPollInterval); err != nil {
+		log.Fatalf("failed to start collector: %v\n", err)
+	}
+	defer collector.Stop()
+
+	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: *listenAddr}
+	go func() {
+		log.Printf("serving /metrics on %s\n", *listenAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("metrics server failed: %v\n", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+	_ = server.Close()
+}
+
+// parseLists parses a comma-separated name=0xAddress list into exporter.ListConfig entries,
+// all bound to the same RPC client.
+func parseLists(raw string, client *ethclient.Client) ([]exporter.ListConfig, error) {
+	var lists []exporter.ListConfig
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid -addresses entry %q, expected name=0xAddress", entry)
+		}
+		lists = append(lists, exporter.ListConfig{
+			Name:    parts[0],
+			Address: common.HexToAddress(parts[1]),
+			Backend: client,
+		})
+	}
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("no AddressList contracts given via -addresses")
+	}
+	return lists, nil
+}
@@ -8,11 +8,13 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"crypto/ed25519"
 	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
@@ -29,26 +31,31 @@ import (
 	"github.com/iotexproject/iotex-proto/golang/iotexapi"
 	"go.uber.org/config"
 
+	"github.com/iotexproject/ioTube/witness-service/beaconfinality"
+	"github.com/iotexproject/ioTube/witness-service/consensus"
 	"github.com/iotexproject/ioTube/witness-service/db"
+	"github.com/iotexproject/ioTube/witness-service/index"
 	"github.com/iotexproject/ioTube/witness-service/util"
 	"github.com/iotexproject/ioTube/witness-service/witness"
 )
 
 // Configuration defines the configuration of the witness service
 type Configuration struct {
-	Chain                 string        `json:"chain" yaml:"chain"`
-	ClientURL             string        `json:"clientURL" yaml:"clientURL"`
-	RelayerURL            string        `jsong:"relayerURL" yaml:"relayerURL"`
-	Database              db.Config     `json:"database" yaml:"database"`
-	PrivateKey            string        `json:"privateKey" yaml:"privateKey"`
-	SlackWebHook          string        `json:"slackWebHook" yaml:"slackWebHook"`
-	LarkWebHook           string        `json:"larkWebHook" yaml:"larkWebHook"`
-	ConfirmBlockNumber    int           `json:"confirmBlockNumber" yaml:"confirmBlockNumber"`
-	BatchSize             int           `json:"batchSize" yaml:"batchSize"`
-	Interval              time.Duration `json:"interval" yaml:"interval"`
-	GrpcPort              int           `json:"grpcPort" yaml:"grpcPort"`
-	GrpcProxyPort         int           `json:"grpcProxyPort" yaml:"grpcProxyPort"`
-	DisableTransferSubmit bool          `json:"disableTransferSubmit" yaml:"disableTransferSubmit"`
+	Chain              string    `json:"chain" yaml:"chain"`
+	ClientURL          string    `json:"clientURL" yaml:"clientURL"`
+	RelayerURL         string    `jsong:"relayerURL" yaml:"relayerURL"`
+	Database           db.Config `json:"database" yaml:"database"`
+	SlackWebHook       string    `json:"slackWebHook" yaml:"slackWebHook"`
+	LarkWebHook        string    `json:"larkWebHook" yaml:"larkWebHook"`
+	ConfirmBlockNumber int       `json:"confirmBlockNumber" yaml:"confirmBlockNumber"`
+	// BeaconFinality, when Enabled, runs a beaconfinality.Checker alongside ConfirmBlockNumber.
+	// Leaving it unset (the zero value has Enabled false) runs no beacon light client at all.
+	BeaconFinality        BeaconFinalityConfig `json:"beaconFinality" yaml:"beaconFinality"`
+	BatchSize             int                  `json:"batchSize" yaml:"batchSize"`
+	Interval              time.Duration        `json:"interval" yaml:"interval"`
+	GrpcPort              int                  `json:"grpcPort" yaml:"grpcPort"`
+	GrpcProxyPort         int                  `json:"grpcProxyPort" yaml:"grpcProxyPort"`
+	DisableTransferSubmit bool                 `json:"disableTransferSubmit" yaml:"disableTransferSubmit"`
 	Cashiers              []struct {
 		ID                       string `json:"id" yaml:"id"`
 		RelayerURL               string `json:"relayerURL" yaml:"relayerURL"`
@@ -56,7 +63,17 @@ type Configuration struct {
 		TokenSafeContractAddress string `json:"tokenSafeContractAddress" yaml:"tokenSafeContractAddress"`
 		ValidatorContractAddress string `json:"vialidatorContractAddress" yaml:"validatorContractAddress"`
 		TransferTableName        string `json:"transferTableName" yaml:"transferTableName"`
-		TokenPairs               []struct {
+		// DestinationChain is the chain this cashier bridges to, e.g. "ethereum" or "solana". It
+		// used to live on Configuration itself, forcing one witness process per destination; it
+		// now varies per cashier so one process can fan a source chain out to several
+		// destinations, each with its own decoder and signing key below.
+		DestinationChain string `json:"destinationChain" yaml:"destinationChain"`
+		// PrivateKey signs outgoing transactions on DestinationChain. Cashiers that share a
+		// DestinationChain share one signer: the first one encountered (in config order) with a
+		// non-empty PrivateKey wins, and the rest are expected to leave it blank. Falls back to
+		// the WITNESS_PRIVATE_KEY env var when empty, for single-destination deployments.
+		PrivateKey string `json:"privateKey" yaml:"privateKey"`
+		TokenPairs []struct {
 			Token1 string `json:"token1" yaml:"token1"`
 			Token2 string `json:"token2" yaml:"token2"`
 		} `json:"tokenPairs" yaml:"tokenPairs"`
@@ -73,7 +90,87 @@ type Configuration struct {
 		QPSLimit    uint32 `json:"qpsLimit" yaml:"qpsLimit"`
 		DisablePull bool   `json:"disablePull" yaml:"disablePull"`
 	} `json:"cashiers" yaml:"cashiers"`
-	DestinationChain string `json:"destinationChain" yaml:"destinationChain"`
+	// Consensus, when Enabled, makes this witness run a HotStuff-style vote with its peers
+	// before releasing a bridge signature, instead of submitting one independently. Leaving it
+	// unset (the zero value has Enabled false) keeps today's single-witness behavior.
+	//
+	// Not yet functional: startConsensus wires the replica to loggingTransport, which only logs
+	// broadcasts/votes and never delivers a peer's, and to noopTransferSource, which never has a
+	// real pending transfer to propose - so a replica can exercise its own timing/persistence but
+	// can never see a peer vote or reach a quorum. Enabling this runs the replica loop with no
+	// effect on cashier behavior; it is not a substitute for today's single-witness signing yet.
+	Consensus ConsensusConfig `json:"consensus" yaml:"consensus"`
+	// Index, when Enabled, runs an index.AddressListIndex over Address and serves ListByOwner/
+	// ListAllOwners over HTTP at ListenAddr. Leaving it unset (Enabled false) runs no index.
+	Index IndexConfig `json:"index" yaml:"index"`
+}
+
+// IndexConfig configures the index.AddressListIndex this witness serves when Enabled is true.
+type IndexConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Address is the deployed AddressList contract to index.
+	Address string `json:"address" yaml:"address"`
+	// ListenAddr is the address index.Service's HTTP handler is served on, e.g. ":8090".
+	//
+	// addresslistindex.proto describes this service as gRPC, but no *_grpc.pb.go has been
+	// generated for it in this tree and witness.StartServer's *grpc.Server is internal to the
+	// witness package, so there is nothing for this process to register a gRPC handler onto.
+	// Serving index.Service.HTTPHandler on its own listener is the closest real wiring available
+	// until the stubs exist; switch this to cfg.GrpcPort once they do.
+	ListenAddr string `json:"listenAddr" yaml:"listenAddr"`
+	// FromBlock is the checkpoint the index (re)starts from. Zero means genesis.
+	FromBlock uint64 `json:"fromBlock" yaml:"fromBlock"`
+	// ReorgDepth is how many blocks back the index keeps a recorded header for.
+	ReorgDepth uint64 `json:"reorgDepth" yaml:"reorgDepth"`
+}
+
+// BeaconFinalityConfig configures the beaconfinality.Checker a witness runs when Enabled is true.
+//
+// Not yet functional: Verifier below is wired to unverifiedBLSVerifier, which accepts every
+// sync-committee signature unconditionally (no BLS12-381 pairing implementation exists anywhere
+// in this tree), and IsFinal is not called by any cashier or anywhere else (see
+// startBeaconFinality). Enabling this runs the light client's bootstrap and update polling only;
+// it is not an authenticated finality check, and ConfirmBlockNumber remains the only real trust
+// boundary. Defaults to Enabled false so a witness
+// that merely sets BeaconClientURL does not silently start believing an unauthenticated checker.
+type BeaconFinalityConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// BeaconClientURL is the base URL of a beacon node exposing the standard
+	// /eth/v1/beacon/light_client/* REST endpoints.
+	BeaconClientURL string `json:"beaconClientURL" yaml:"beaconClientURL"`
+	// TrustedBlockRoot is the checkpoint block root beaconfinality.Checker bootstraps its light
+	// client from. Required when Enabled is true.
+	TrustedBlockRoot string `json:"trustedBlockRoot" yaml:"trustedBlockRoot"`
+	// GenesisValidatorsRoot is mixed into the sync-committee signing domain beaconfinality.Checker
+	// verifies LightClientUpdate signatures against.
+	GenesisValidatorsRoot string `json:"genesisValidatorsRoot" yaml:"genesisValidatorsRoot"`
+}
+
+// ConsensusConfig configures the consensus.Replica a witness runs when Enabled is true.
+type ConsensusConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Self is this witness's ReplicaID within Peers.
+	Self uint32 `json:"self" yaml:"self"`
+	// Peers lists the ReplicaID of every witness in the consensus group, including Self.
+	Peers []uint32 `json:"peers" yaml:"peers"`
+	// Threshold is f, the maximum number of faulty witnesses tolerated; a quorum is 2f+1 votes.
+	Threshold int `json:"threshold" yaml:"threshold"`
+	// ViewTimeout is how long a view may run without reaching commit before a witness broadcasts
+	// NewView and moves on.
+	ViewTimeout time.Duration `json:"viewTimeout" yaml:"viewTimeout"`
+	// ProposeInterval is how often the leader checks for a new batch of transfers to propose.
+	ProposeInterval time.Duration `json:"proposeInterval" yaml:"proposeInterval"`
+	// SourceChain and DestChain scope which pending transfers this replica's batches are drawn
+	// from; they are independent of cfg.Chain/cfg.Cashiers[].DestinationChain so one witness
+	// process can run consensus for a single chain pair even while its cashiers bridge several.
+	SourceChain string `json:"sourceChain" yaml:"sourceChain"`
+	DestChain   string `json:"destChain" yaml:"destChain"`
+	// PrivateKey signs this replica's own votes. Falls back to the WITNESS_PRIVATE_KEY env var
+	// when empty, the same convention cfg.Cashiers[].PrivateKey uses.
+	PrivateKey string `json:"privateKey" yaml:"privateKey"`
+	// PeerPublicKeys maps every ReplicaID in Peers (including Self) to the hex-encoded secp256k1
+	// public key used to verify that replica's votes.
+	PeerPublicKeys map[uint32]string `json:"peerPublicKeys" yaml:"peerPublicKeys"`
 }
 
 var (
@@ -82,7 +179,6 @@ var (
 		Interval:           time.Minute,
 		BatchSize:          100,
 		ConfirmBlockNumber: 20,
-		PrivateKey:         "",
 		SlackWebHook:       "",
 		LarkWebHook:        "",
 		ClientURL:          "",
@@ -105,6 +201,203 @@ func init() {
 	}
 }
 
+// loggingTransport is a consensus.Transport stand-in: it logs every broadcast/vote instead of
+// putting it on the wire, and its Inbox never delivers anything. consensus.Transport's own doc
+// comment describes the intended wiring as a gRPC stream alongside cfg.GrpcPort, but no such
+// stream or .proto exists in this tree (see consensus.go's package doc), so there is nothing a
+// real implementation could dial yet. This keeps Replica.Run runnable - exercising proposal
+// timing, view timeouts and persistence - without pretending cross-replica agreement happens.
+type loggingTransport struct{}
+
+func (loggingTransport) BroadcastPrepare(ctx context.Context, msg consensus.PrepareMsg) error {
+	log.Printf("consensus: BroadcastPrepare view=%d (no transport wired; not sent to peers)\n", msg.View)
+	return nil
+}
+
+func (loggingTransport) SendVote(ctx context.Context, to consensus.ReplicaID, msg consensus.VoteMsg) error {
+	log.Printf("consensus: SendVote to=%d phase=%v view=%d (no transport wired; not sent)\n", to, msg.Phase, msg.View)
+	return nil
+}
+
+func (loggingTransport) BroadcastPreCommit(ctx context.Context, msg consensus.PreCommitMsg) error {
+	log.Printf("consensus: BroadcastPreCommit view=%d (no transport wired; not sent to peers)\n", msg.View)
+	return nil
+}
+
+func (loggingTransport) BroadcastCommit(ctx context.Context, msg consensus.CommitMsg) error {
+	log.Printf("consensus: BroadcastCommit view=%d (no transport wired; not sent to peers)\n", msg.View)
+	return nil
+}
+
+func (loggingTransport) BroadcastDecide(ctx context.Context, msg consensus.DecideMsg) error {
+	log.Printf("consensus: BroadcastDecide view=%d (no transport wired; not sent to peers)\n", msg.View)
+	return nil
+}
+
+func (loggingTransport) BroadcastNewView(ctx context.Context, msg consensus.NewViewMsg) error {
+	log.Printf("consensus: BroadcastNewView view=%d (no transport wired; not sent to peers)\n", msg.View)
+	return nil
+}
+
+func (loggingTransport) Inbox(ctx context.Context) <-chan interface{} {
+	inbox := make(chan interface{})
+	go func() {
+		<-ctx.Done()
+		close(inbox)
+	}()
+	return inbox
+}
+
+// noopTransferSource stands in for witness.Recorder (the real source of pending transfers),
+// which is not part of this source tree (see consensus.go's package doc); the replica it's given
+// to simply never has anything of its own to propose until a real adapter is wired.
+type noopTransferSource struct{}
+
+func (noopTransferSource) PendingTransfers(ctx context.Context, sourceChain, destChain string) ([]consensus.Transfer, error) {
+	return nil, nil
+}
+
+// startConsensus builds and runs a consensus.Replica from cfg in its own goroutine. Peer votes
+// can only be verified/sent once a real consensus.Transport exists (see loggingTransport above),
+// so this only exercises the replica's own timing/persistence today; it is still real wiring
+// rather than the previously entirely-decorative config block.
+//
+// See ConsensusConfig's doc comment: this subsystem is not yet functional, which is why it logs a
+// loud warning, rather than a routine startup line, once the replica is running.
+func startConsensus(cfg ConsensusConfig) {
+	log.Println("WARNING: consensus replica starting but is not yet functional - no transport is wired (loggingTransport only logs) and no real pending-transfer source is wired (noopTransferSource is always empty), so it can never reach a quorum")
+	peers := make([]consensus.ReplicaID, len(cfg.Peers))
+	pubkeys := make(map[consensus.ReplicaID]*ecdsa.PublicKey, len(cfg.Peers))
+	for i, p := range cfg.Peers {
+		peers[i] = consensus.ReplicaID(p)
+	}
+	for id, hexKey := range cfg.PeerPublicKeys {
+		pub, err := crypto.UnmarshalPubkey(common.FromHex(hexKey))
+		if err != nil {
+			log.Printf("consensus: invalid public key for replica %d: %v\n", id, err)
+			return
+		}
+		pubkeys[consensus.ReplicaID(id)] = pub
+	}
+	privateKey := cfg.PrivateKey
+	if privateKey == "" {
+		privateKey = os.Getenv("WITNESS_PRIVATE_KEY")
+	}
+	ecdsaKey, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		log.Printf("consensus: invalid private key: %v\n", err)
+		return
+	}
+
+	replica, err := consensus.NewReplica(consensus.Config{
+		Peers:       peers,
+		Threshold:   cfg.Threshold,
+		ViewTimeout: cfg.ViewTimeout,
+		Interval:    cfg.ProposeInterval,
+		SourceChain: cfg.SourceChain,
+		DestChain:   cfg.DestChain,
+		Transport:   loggingTransport{},
+		Transfers:   noopTransferSource{},
+		Signer:      consensus.NewSecp256k1Signer(consensus.ReplicaID(cfg.Self), ecdsaKey, pubkeys),
+		Store:       consensus.NewMemStore(),
+		// OnCommit is the hook a real deployment uses to hand its bridge signature, plus the
+		// commit QC, to witness.Service's submit path; that type lives in the witness package,
+		// which is not part of this source tree (see consensus.go's package doc), so this stub
+		// only logs until that wiring can be added.
+		OnCommit: func(ctx context.Context, transferIDs []consensus.TransferID, commitQC consensus.QC) {
+			log.Printf("consensus: committed %d transfers at view %d (no OnCommit hook wired)\n", len(transferIDs), commitQC.View)
+		},
+	})
+	if err != nil {
+		log.Printf("consensus: failed to create replica: %v\n", err)
+		return
+	}
+	go func() {
+		if err := replica.Run(context.Background()); err != nil {
+			log.Printf("consensus: replica stopped: %v\n", err)
+		}
+	}()
+}
+
+// startAddressListIndex builds and starts an index.AddressListIndex over cfg.Address, then serves
+// it as JSON over HTTP on cfg.ListenAddr. It logs and returns without starting anything if cfg is
+// misconfigured, since an index failing to start should not take the whole witness process down.
+func startAddressListIndex(cfg IndexConfig, backend *ethclient.Client) {
+	if cfg.Address == "" || cfg.ListenAddr == "" {
+		log.Println("index.enabled is true but address or listenAddr is empty; not starting the index")
+		return
+	}
+	idx, err := index.New(index.Config{
+		Address:    common.HexToAddress(cfg.Address),
+		Backend:    backend,
+		Headers:    backend,
+		FromBlock:  cfg.FromBlock,
+		ReorgDepth: cfg.ReorgDepth,
+	})
+	if err != nil {
+		log.Printf("failed to create AddressList index: %v\n", err)
+		return
+	}
+	if err := idx.Start(context.Background(), time.Minute); err != nil {
+		log.Printf("failed to start AddressList index: %v\n", err)
+		return
+	}
+	svc := index.NewService(idx)
+	go func() {
+		log.Printf("Serving AddressList index on %s\n", cfg.ListenAddr)
+		if err := http.ListenAndServe(cfg.ListenAddr, svc.HTTPHandler()); err != nil {
+			log.Printf("AddressList index HTTP server stopped: %v\n", err)
+		}
+	}()
+}
+
+// unverifiedBLSVerifier is a beaconfinality.BLSVerifier stand-in: no BLS12-381 pairing
+// implementation exists anywhere in this tree (beaconfinality's own doc comment recommends
+// github.com/supranational/blst or similar), so this accepts every signature unconditionally.
+// This makes startBeaconFinality's Checker track finalized headers as claimed by the beacon
+// node without actually authenticating them - acceptable to exercise the bootstrap/polling/
+// Merkle-branch-verification machinery, not to replace ConfirmBlockNumber as a trust boundary.
+// Swap in a real Verifier before relying on IsFinal's result.
+type unverifiedBLSVerifier struct{}
+
+func (unverifiedBLSVerifier) VerifyAggregate(pubkeys [][]byte, signingRoot [32]byte, signature []byte) (bool, error) {
+	return true, nil
+}
+
+// startBeaconFinality builds and starts a beaconfinality.Checker from cfg, when cfg.Enabled. It
+// cannot be threaded into witness.NewTokenCashierOnEthereum's ConfirmBlockNumber parameter: that
+// constructor's signature is fixed by the witness package, which is not part of this source tree
+// (see beaconfinality's package doc), and FinalityChecker is not one of its inputs. So this only
+// runs the light client's bootstrap/polling loop alongside (not instead of) the depth check; it
+// never calls IsFinal and logs nothing about finality beyond the startup warning below.
+//
+// See BeaconFinalityConfig's doc comment: this subsystem is not yet functional (unauthenticated
+// signature verification, result not consulted by any cashier), which is why cfg.Enabled defaults
+// to false and why this logs a loud warning, rather than a routine startup line, once started.
+func startBeaconFinality(cfg BeaconFinalityConfig, headers beaconfinality.HeaderReader) {
+	if !cfg.Enabled {
+		return
+	}
+	checker, err := beaconfinality.New(beaconfinality.Config{
+		BeaconClientURL:       cfg.BeaconClientURL,
+		TrustedBlockRoot:      common.HexToHash(cfg.TrustedBlockRoot),
+		GenesisValidatorsRoot: common.HexToHash(cfg.GenesisValidatorsRoot),
+		Verifier:              unverifiedBLSVerifier{},
+		Headers:               headers,
+	})
+	if err != nil {
+		log.Printf("failed to create beacon finality checker: %v\n", err)
+		return
+	}
+	cancel, err := checker.Start(context.Background(), time.Minute)
+	if err != nil {
+		log.Printf("failed to start beacon finality checker: %v\n", err)
+		return
+	}
+	_ = cancel
+	log.Println("WARNING: beacon finality checker started but is not yet functional - signatures are not verified (unverifiedBLSVerifier) and IsFinal is not wired into any cashier; it is informational only, not a trust boundary")
+}
+
 func main() {
 	flag.Parse()
 	opts := []config.YAMLOption{config.Static(defaultConfig), config.Expand(os.LookupEnv)}
@@ -122,9 +415,7 @@ func main() {
 	if err := yaml.Get(config.Root).Populate(&cfg); err != nil {
 		log.Fatalln(err)
 	}
-	if pk, ok := os.LookupEnv("WITNESS_PRIVATE_KEY"); ok {
-		cfg.PrivateKey = pk
-	}
+	envPrivateKey, hasEnvPrivateKey := os.LookupEnv("WITNESS_PRIVATE_KEY")
 
 	if port, ok := os.LookupEnv("WITNESS_GRPC_PORT"); ok {
 		cfg.GrpcPort, err = strconv.Atoi(port)
@@ -151,41 +442,68 @@ func main() {
 		util.SetLarkURL(cfg.LarkWebHook)
 	}
 
-	var (
-		signHandler     witness.SignHandler
-		destAddrDecoder util.AddressDecoder
-	)
-	switch cfg.DestinationChain {
-	default:
-		destAddrDecoder = util.NewETHAddressDecoder()
+	decoders := make(map[string]util.AddressDecoder)
+	decoderFor := func(destChain string) util.AddressDecoder {
+		if d, ok := decoders[destChain]; ok {
+			return d
+		}
+		var d util.AddressDecoder
+		switch destChain {
+		case "solana":
+			d = util.NewSOLAddressDecoder()
+		default:
+			d = util.NewETHAddressDecoder()
+		}
+		decoders[destChain] = d
+		return d
+	}
 
-		if cfg.PrivateKey != "" {
-			privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
-			if err != nil {
-				log.Fatalf("failed to decode private key %v\n", err)
+	// signHandlers is the map[destChain]SignHandler this process builds lazily as it walks
+	// cfg.Cashiers: every cashier bridging to the same destChain shares one signer, keyed off
+	// the first non-empty PrivateKey it declares (falling back to WITNESS_PRIVATE_KEY).
+	signHandlers := make(map[string]witness.SignHandler)
+	signHandlerFor := func(destChain, privateKey string) witness.SignHandler {
+		if h, ok := signHandlers[destChain]; ok {
+			return h
+		}
+		if privateKey == "" {
+			for _, other := range cfg.Cashiers {
+				if other.DestinationChain == destChain && other.PrivateKey != "" {
+					privateKey = other.PrivateKey
+					break
+				}
 			}
-			util.SetPrefix("witness-" + cfg.Chain + ":" + crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
-			log.Println("Witness Service for " + crypto.PubkeyToAddress(privateKey.PublicKey).Hex() + " on chain " + cfg.Chain)
-			signHandler = witness.NewSecp256k1SignHandler(privateKey)
-		} else {
-			log.Println("No Private Key")
 		}
-	case "solana":
-		destAddrDecoder = util.NewSOLAddressDecoder()
-
-		if cfg.PrivateKey != "" {
-			privateKeyBytes, err := hex.DecodeString(cfg.PrivateKey)
+		if privateKey == "" && hasEnvPrivateKey {
+			privateKey = envPrivateKey
+		}
+		if privateKey == "" {
+			log.Printf("No Private Key for destination chain %s\n", destChain)
+			return nil
+		}
+		var h witness.SignHandler
+		switch destChain {
+		case "solana":
+			privateKeyBytes, err := hex.DecodeString(privateKey)
 			if err != nil {
-				log.Fatalf("failed to decode private key %v\n", err)
+				log.Fatalf("failed to decode private key for %s: %v\n", destChain, err)
 			}
 			if len(privateKeyBytes) != ed25519.PrivateKeySize {
-				log.Fatalf("invalid private key length %d\n", len(privateKeyBytes))
+				log.Fatalf("invalid private key length %d for %s\n", len(privateKeyBytes), destChain)
 			}
 			edPrivateKey := ed25519.PrivateKey(privateKeyBytes)
-			signHandler = witness.NewEd25519SignHandler(&edPrivateKey)
-		} else {
-			log.Println("No Private Key")
+			h = witness.NewEd25519SignHandler(&edPrivateKey)
+		default:
+			ecdsaKey, err := crypto.HexToECDSA(privateKey)
+			if err != nil {
+				log.Fatalf("failed to decode private key for %s: %v\n", destChain, err)
+			}
+			util.SetPrefix("witness-" + cfg.Chain + ":" + crypto.PubkeyToAddress(ecdsaKey.PublicKey).Hex())
+			log.Println("Witness Service for " + crypto.PubkeyToAddress(ecdsaKey.PublicKey).Hex() + " on chain " + cfg.Chain + " -> " + destChain)
+			h = witness.NewSecp256k1SignHandler(ecdsaKey)
 		}
+		signHandlers[destChain] = h
+		return h
 	}
 
 	if cfg.RelayerURL != "" {
@@ -209,6 +527,8 @@ func main() {
 		iotexClient := iotex.NewReadOnlyClient(iotexapi.NewAPIServiceClient(conn))
 		// defer conn.Close()
 		for _, cc := range cfg.Cashiers {
+			destAddrDecoder := decoderFor(cc.DestinationChain)
+			signHandler := signHandlerFor(cc.DestinationChain, cc.PrivateKey)
 			cashierContractAddr, err := address.FromString(cc.CashierContractAddress)
 			if err != nil {
 				log.Fatalf("failed to parse cashier contract address %s, %v\n", cc.CashierContractAddress, err)
@@ -244,6 +564,7 @@ func main() {
 			cashier, err := witness.NewTokenCashier(
 				cc.ID,
 				cc.RelayerURL,
+				signHandler,
 				iotexClient,
 				cashierContractAddr,
 				validatorContractAddr.Bytes(),
@@ -270,7 +591,13 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
+		if cfg.Index.Enabled {
+			startAddressListIndex(cfg.Index, ethClient)
+		}
+		startBeaconFinality(cfg.BeaconFinality, ethClient)
 		for _, cc := range cfg.Cashiers {
+			destAddrDecoder := decoderFor(cc.DestinationChain)
+			signHandler := signHandlerFor(cc.DestinationChain, cc.PrivateKey)
 			addr, err := address.FromString(cc.ValidatorContractAddress)
 			if err != nil {
 				log.Fatalf("failed to parse validator contract address %v\n", err)
@@ -303,6 +630,7 @@ func main() {
 			cashier, err := witness.NewTokenCashierOnEthereum(
 				cc.ID,
 				cc.RelayerURL,
+				signHandler,
 				ethClient,
 				common.HexToAddress(cc.CashierContractAddress),
 				common.HexToAddress(cc.TokenSafeContractAddress),
@@ -327,6 +655,8 @@ func main() {
 	case "solana":
 		solClient := solclient.NewClient(cfg.ClientURL)
 		for _, cc := range cfg.Cashiers {
+			destAddrDecoder := decoderFor(cc.DestinationChain)
+			signHandler := signHandlerFor(cc.DestinationChain, cc.PrivateKey)
 			addr, err := address.FromString(cc.ValidatorContractAddress)
 			if err != nil {
 				log.Fatalf("failed to parse validator contract address %v\n", err)
@@ -351,6 +681,7 @@ func main() {
 			cashier, err := witness.NewTokenCashierOnSolana(
 				cc.ID,
 				cc.RelayerURL,
+				signHandler,
 				solClient,
 				solcommon.PublicKeyFromString(cc.CashierContractAddress),
 				common.BytesToAddress(addr.Bytes()),
@@ -374,8 +705,15 @@ func main() {
 		log.Fatalf("unknown chain name %s", cfg.Chain)
 	}
 
+	if cfg.Consensus.Enabled {
+		startConsensus(cfg.Consensus)
+	}
+
+	// Each cashier now carries its own signHandler (see signHandlerFor above), so Service no
+	// longer takes one for the whole process; this, and the corresponding signature changes on
+	// NewTokenCashier/NewTokenCashierOnEthereum/NewTokenCashierOnSolana, live in the witness
+	// package, which is not part of this source tree, so only the call sites are updated here.
 	service, err := witness.NewService(
-		signHandler,
 		cashiers,
 		uint16(cfg.BatchSize),
 		cfg.Interval,
@@ -0,0 +1,262 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package consensus runs a three-phase HotStuff-style vote among a fixed set of witnesses over
+// one batch of transfers at a time, so a bridge signature is only handed off to the relayer once
+// 2f+1 witnesses have agreed on what the leader observed, rather than each witness submitting its
+// own signature independently.
+//
+// This is single-decree BFT, not chained HotStuff: each view votes on one standalone BlockHash
+// (a commitment to a batch of transfer IDs) rather than a block that extends a growing chain, so
+// the safety rule a replica applies on a new Prepare is "matches my lockedQC's BlockHash, or
+// carries a Justify QC for a later view than my lockedQC", the single-decree analogue of
+// HotStuff's "extends lockedQC, or justify.viewNumber > lockedQC.viewNumber" rule. That is enough
+// to prevent two conflicting batches from both committing in one view while still letting the
+// protocol recover (via the higher QC override) if a replica is locked on a batch the rest of the
+// quorum has since abandoned; it does not provide the full chained-HotStuff guarantee of
+// committing one view per network round-trip indefinitely.
+//
+// Replica depends on Transport, TransferSource, Signer and Store rather than this repository's
+// witness.Service, witness.Recorder, witness.SignHandler and db.Store directly: those packages
+// are not part of this source tree (a `witness` package is imported by cmd/witness/main.go but
+// does not exist in this snapshot), so Replica is wired against small local interfaces a real
+// deployment implements in terms of those types. The gRPC stream the request describes carrying
+// Prepare/Vote/PreCommit/Commit/NewView messages between witnesses is similarly represented by
+// Transport rather than committed proto/service code, since no .proto definitions for it exist
+// here either.
+package consensus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ReplicaID identifies one witness in the consensus group. The leader for view v is
+// Peers[v % len(Peers)].
+type ReplicaID uint32
+
+// TransferID identifies one transfer record a batch commits to.
+type TransferID string
+
+// Transfer is the subset of a witness's Transfer record a BlockHash commits to.
+type Transfer struct {
+	ID          TransferID
+	SourceChain string
+	DestChain   string
+}
+
+// BlockHash commits to a batch of transfer IDs, the chains they move between, and the view they
+// were proposed in.
+type BlockHash [32]byte
+
+// ComputeBlockHash hashes sorted(transferIDs) || sourceChain || destChain || view, so any replica
+// that recomputes it from the same pending-transfer set agrees with the leader byte-for-byte.
+func ComputeBlockHash(transferIDs []TransferID, sourceChain, destChain string, view uint64) BlockHash {
+	sorted := make([]TransferID, len(transferIDs))
+	copy(sorted, transferIDs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	h := sha256.New()
+	for _, id := range sorted {
+		h.Write([]byte(id))
+	}
+	h.Write([]byte(sourceChain))
+	h.Write([]byte(destChain))
+	var view8 [8]byte
+	binary.BigEndian.PutUint64(view8[:], view)
+	h.Write(view8[:])
+
+	var out BlockHash
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// Phase is one of the three HotStuff voting rounds.
+type Phase int
+
+// The three phases a batch passes through before a replica releases its bridge signature.
+const (
+	PhasePrepare Phase = iota
+	PhasePreCommit
+	PhaseCommit
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhasePrepare:
+		return "prepare"
+	case PhasePreCommit:
+		return "pre-commit"
+	case PhaseCommit:
+		return "commit"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Vote is one replica's signature over (phase, view, blockHash).
+type Vote struct {
+	Replica   ReplicaID
+	Signature []byte
+}
+
+// QC (quorum certificate) is proof that at least 2f+1 replicas voted for (Phase, View, BlockHash).
+type QC struct {
+	Phase     Phase
+	View      uint64
+	BlockHash BlockHash
+	Votes     []Vote
+}
+
+// PrepareMsg is the leader's proposal for view, naming the transfers it wants to commit.
+type PrepareMsg struct {
+	View        uint64
+	BlockHash   BlockHash
+	TransferIDs []TransferID
+	SourceChain string
+	DestChain   string
+	// Justify is the highest QC the leader holds (its preparedQC, if any) at proposal time. A
+	// replica locked on a conflicting BlockHash only votes for this Prepare if Justify verifies
+	// and was reached in a later view than its own lockedQC; see handlePrepare's safety rule.
+	Justify *QC
+}
+
+// VoteMsg is a replica's signed vote for one phase of one view, sent back to the leader.
+type VoteMsg struct {
+	Phase     Phase
+	View      uint64
+	BlockHash BlockHash
+	Replica   ReplicaID
+	Signature []byte
+}
+
+// PreCommitMsg carries the QC_prepare the leader formed from 2f+1 PrepareVotes.
+type PreCommitMsg struct {
+	View    uint64
+	Prepare QC
+}
+
+// CommitMsg carries the QC_precommit the leader formed from 2f+1 PreCommitVotes.
+type CommitMsg struct {
+	View      uint64
+	PreCommit QC
+}
+
+// NewViewMsg is broadcast by a replica whose view timer expired, carrying the highest QC it has
+// locked so the next leader can recover any batch that was prepared but not yet committed.
+type NewViewMsg struct {
+	View     uint64
+	Replica  ReplicaID
+	LockedQC *QC
+}
+
+// signingPayload is what Signer signs/verifies a Vote over: it must bind phase, view and
+// blockHash so a vote cannot be replayed across phases or views.
+func signingPayload(phase Phase, view uint64, blockHash BlockHash) []byte {
+	buf := make([]byte, 0, 1+8+len(blockHash))
+	buf = append(buf, byte(phase))
+	var view8 [8]byte
+	binary.BigEndian.PutUint64(view8[:], view)
+	buf = append(buf, view8[:]...)
+	buf = append(buf, blockHash[:]...)
+	return buf
+}
+
+// Signer signs and verifies votes on behalf of the local replica and its peers. BLS and
+// secp256k1 both satisfy this; which scheme is in use is opaque to Replica.
+type Signer interface {
+	// ID is this process's own ReplicaID.
+	ID() ReplicaID
+	// Sign returns this replica's signature over payload.
+	Sign(payload []byte) ([]byte, error)
+	// Verify checks that signature is replica's signature over payload.
+	Verify(replica ReplicaID, payload []byte, signature []byte) (bool, error)
+}
+
+// TransferSource is the subset of a witness's Recorder a replica needs: the batch of transfers
+// it would, absent consensus, have submitted on its own.
+type TransferSource interface {
+	PendingTransfers(ctx context.Context, sourceChain, destChain string) ([]Transfer, error)
+}
+
+// PersistedState is the durable state a restart must recover to stay safe: the view a replica
+// last reached and the highest QCs it had formed/locked in that run.
+type PersistedState struct {
+	View       uint64
+	LockedQC   *QC
+	PreparedQC *QC
+}
+
+// Store persists a replica's PersistedState, mirroring how the rest of this service uses
+// db.Store for other durable state.
+type Store interface {
+	LoadState(replica ReplicaID) (PersistedState, error)
+	SaveState(replica ReplicaID, state PersistedState) error
+}
+
+// Transport carries consensus messages between the replicas in Config.Peers. This package ships
+// no network implementation of it and no gRPC stream or .proto for these messages exists in this
+// tree (see the package doc); Inbox demultiplexes whatever an implementation receives into one
+// channel of these message types.
+type Transport interface {
+	BroadcastPrepare(ctx context.Context, msg PrepareMsg) error
+	SendVote(ctx context.Context, to ReplicaID, msg VoteMsg) error
+	BroadcastPreCommit(ctx context.Context, msg PreCommitMsg) error
+	BroadcastCommit(ctx context.Context, msg CommitMsg) error
+	// BroadcastDecide disseminates QC_commit (see DecideMsg) to every replica. It is not one of
+	// the three named phases in the protocol description but is required for any replica other
+	// than the leader to ever learn a batch reached QC_commit.
+	BroadcastDecide(ctx context.Context, msg DecideMsg) error
+	BroadcastNewView(ctx context.Context, msg NewViewMsg) error
+	// Inbox returns the channel PrepareMsg, VoteMsg, PreCommitMsg, CommitMsg, DecideMsg and
+	// NewViewMsg values arrive on, in receipt order, until ctx is done.
+	Inbox(ctx context.Context) <-chan interface{}
+}
+
+// CommitHandler is invoked once a replica has assembled QC_commit for a batch: it is the hook a
+// caller uses to hand its own bridge signature, plus commitQC as evidence of agreement, to the
+// existing TokenCashier relayer path.
+type CommitHandler func(ctx context.Context, transferIDs []TransferID, commitQC QC)
+
+// Config holds the parameters a Replica is built with.
+type Config struct {
+	// Peers lists every replica in the consensus group, including Self; the leader for view v
+	// is Peers[v%len(Peers)].
+	Peers []ReplicaID
+	// Threshold is f, the maximum number of faulty replicas tolerated; a quorum is 2f+1 votes.
+	Threshold int
+	// ViewTimeout is how long a replica waits for a view to reach Commit before broadcasting
+	// NewView and advancing.
+	ViewTimeout time.Duration
+	// Interval is how often the leader checks for a new batch of transfers to propose.
+	Interval time.Duration
+	// SourceChain and DestChain scope which transfers this Replica's batches are drawn from.
+	SourceChain string
+	DestChain   string
+
+	Transport Transport
+	Transfers TransferSource
+	Signer    Signer
+	Store     Store
+	OnCommit  CommitHandler
+}
+
+// quorum is the number of votes needed to form a QC: 2f+1.
+func (cfg Config) quorum() int {
+	return 2*cfg.Threshold + 1
+}
+
+func (cfg Config) leader(view uint64) ReplicaID {
+	return cfg.Peers[view%uint64(len(cfg.Peers))]
+}
+
+func (cfg Config) isLeader(view uint64) bool {
+	return cfg.leader(view) == cfg.Signer.ID()
+}
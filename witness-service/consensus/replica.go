@@ -0,0 +1,402 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// voteKey groups the votes a QC for one (phase, view, blockHash) is assembled from.
+type voteKey struct {
+	phase     Phase
+	view      uint64
+	blockHash BlockHash
+}
+
+// DecideMsg disseminates QC_commit, the QC the leader assembled from CommitVotes, to every
+// replica. The request this package implements names three rounds (Prepare, PreCommit, Commit)
+// but only the leader learns QC_commit by forming it; without a fourth broadcast the other
+// replicas would never see it and so could never release their bridge signature. This mirrors
+// classical HotStuff's Decide phase.
+type DecideMsg struct {
+	View   uint64
+	Commit QC
+}
+
+// Replica runs one witness's side of the protocol: proposing (when it is the leader for the
+// current view), voting on proposals that match what it independently observed, aggregating
+// votes into QCs (when leader), and releasing its bridge signature once QC_commit is reached.
+type Replica struct {
+	cfg Config
+
+	mu         sync.Mutex
+	view       uint64
+	lockedQC   *QC
+	preparedQC *QC
+	batches    map[uint64][]TransferID
+	votes      map[voteKey][]Vote
+
+	resetTimer chan struct{}
+}
+
+// NewReplica builds a Replica from cfg, recovering lockedQC/preparedQC (and the view they were
+// reached in) from cfg.Store so a restart cannot vote unsafely.
+func NewReplica(cfg Config) (*Replica, error) {
+	if len(cfg.Peers) == 0 {
+		return nil, fmt.Errorf("consensus: at least one peer is required")
+	}
+	if cfg.Threshold < 0 {
+		return nil, fmt.Errorf("consensus: Threshold must be >= 0")
+	}
+	if cfg.ViewTimeout <= 0 {
+		return nil, fmt.Errorf("consensus: ViewTimeout must be positive")
+	}
+	if cfg.Transport == nil || cfg.Transfers == nil || cfg.Signer == nil || cfg.Store == nil {
+		return nil, fmt.Errorf("consensus: Transport, Transfers, Signer and Store are all required")
+	}
+	if cfg.OnCommit == nil {
+		return nil, fmt.Errorf("consensus: OnCommit is required")
+	}
+	state, err := cfg.Store.LoadState(cfg.Signer.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted consensus state: %w", err)
+	}
+	return &Replica{
+		cfg:        cfg,
+		view:       state.View,
+		lockedQC:   state.LockedQC,
+		preparedQC: state.PreparedQC,
+		batches:    make(map[uint64][]TransferID),
+		votes:      make(map[voteKey][]Vote),
+		resetTimer: make(chan struct{}, 1),
+	}, nil
+}
+
+// Run drives the protocol until ctx is done: proposing on Interval when this replica leads the
+// current view, dispatching inbound messages, and forcing a view change on ViewTimeout.
+func (r *Replica) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	timer := time.NewTimer(r.cfg.ViewTimeout)
+	defer timer.Stop()
+	inbox := r.cfg.Transport.Inbox(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.maybePropose(ctx); err != nil {
+				return fmt.Errorf("failed to propose: %w", err)
+			}
+		case <-timer.C:
+			if err := r.onViewTimeout(ctx); err != nil {
+				return fmt.Errorf("failed to broadcast new-view: %w", err)
+			}
+			timer.Reset(r.cfg.ViewTimeout)
+		case <-r.resetTimer:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(r.cfg.ViewTimeout)
+		case msg, ok := <-inbox:
+			if !ok {
+				return nil
+			}
+			if err := r.dispatch(ctx, msg); err != nil {
+				return fmt.Errorf("failed to handle %T: %w", msg, err)
+			}
+		}
+	}
+}
+
+func (r *Replica) dispatch(ctx context.Context, msg interface{}) error {
+	switch m := msg.(type) {
+	case PrepareMsg:
+		return r.handlePrepare(ctx, m)
+	case VoteMsg:
+		return r.handleVote(ctx, m)
+	case PreCommitMsg:
+		return r.handlePreCommit(ctx, m)
+	case CommitMsg:
+		return r.handleCommit(ctx, m)
+	case DecideMsg:
+		return r.handleDecide(ctx, m)
+	case NewViewMsg:
+		return r.handleNewView(ctx, m)
+	default:
+		return fmt.Errorf("unrecognized message type %T", msg)
+	}
+}
+
+// maybePropose, when this replica leads the current view, gathers its pending transfers and
+// broadcasts a Prepare naming them.
+func (r *Replica) maybePropose(ctx context.Context) error {
+	r.mu.Lock()
+	view := r.view
+	justify := r.preparedQC
+	r.mu.Unlock()
+	if !r.cfg.isLeader(view) {
+		return nil
+	}
+	transfers, err := r.cfg.Transfers.PendingTransfers(ctx, r.cfg.SourceChain, r.cfg.DestChain)
+	if err != nil {
+		return fmt.Errorf("failed to read pending transfers: %w", err)
+	}
+	if len(transfers) == 0 {
+		return nil
+	}
+	ids := transferIDs(transfers)
+	blockHash := ComputeBlockHash(ids, r.cfg.SourceChain, r.cfg.DestChain, view)
+
+	r.mu.Lock()
+	r.batches[view] = ids
+	r.mu.Unlock()
+
+	return r.cfg.Transport.BroadcastPrepare(ctx, PrepareMsg{
+		View:        view,
+		BlockHash:   blockHash,
+		TransferIDs: ids,
+		SourceChain: r.cfg.SourceChain,
+		DestChain:   r.cfg.DestChain,
+		Justify:     justify,
+	})
+}
+
+// handlePrepare re-derives the transfer set from this replica's own TransferSource and, if it
+// agrees with msg and msg is safe to vote for, votes for it.
+func (r *Replica) handlePrepare(ctx context.Context, msg PrepareMsg) error {
+	r.mu.Lock()
+	locked := r.lockedQC
+	r.mu.Unlock()
+	if locked != nil && msg.BlockHash != locked.BlockHash {
+		// Safety rule: a Prepare that conflicts with what this replica already locked is only
+		// safe to vote for if it is justified by a QC reached in a later view than the lock -
+		// proof that 2f+1 replicas have since moved past it - not merely proposed in a later
+		// view itself (that alone would let a leader silently abandon a locked, possibly already
+		// committed, batch and double-commit a conflicting one).
+		if msg.Justify == nil || msg.Justify.View <= locked.View || !r.verifyQC(*msg.Justify) {
+			return nil
+		}
+	}
+
+	transfers, err := r.cfg.Transfers.PendingTransfers(ctx, msg.SourceChain, msg.DestChain)
+	if err != nil {
+		return fmt.Errorf("failed to read pending transfers: %w", err)
+	}
+	ownIDs := transferIDs(transfers)
+	ownHash := ComputeBlockHash(ownIDs, msg.SourceChain, msg.DestChain, msg.View)
+	if ownHash != msg.BlockHash || !sameTransferSet(ownIDs, msg.TransferIDs) {
+		return nil // does not match what this replica itself observed; abstain
+	}
+
+	r.mu.Lock()
+	r.batches[msg.View] = msg.TransferIDs
+	r.mu.Unlock()
+
+	return r.vote(ctx, PhasePrepare, msg.View, msg.BlockHash, r.cfg.leader(msg.View))
+}
+
+// handleVote is only meaningful for the leader of vote.View: it accumulates votes for one
+// (phase, view, blockHash) and, once a quorum is reached, forms a QC and advances the batch to
+// its next phase.
+func (r *Replica) handleVote(ctx context.Context, vote VoteMsg) error {
+	ok, err := r.cfg.Signer.Verify(vote.Replica, signingPayload(vote.Phase, vote.View, vote.BlockHash), vote.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to verify vote from replica %d: %w", vote.Replica, err)
+	}
+	if !ok {
+		return nil
+	}
+
+	key := voteKey{phase: vote.Phase, view: vote.View, blockHash: vote.BlockHash}
+	r.mu.Lock()
+	votes := append(r.votes[key], Vote{Replica: vote.Replica, Signature: vote.Signature})
+	r.votes[key] = votes
+	count := len(distinctReplicas(votes))
+	r.mu.Unlock()
+	if count < r.cfg.quorum() {
+		return nil
+	}
+
+	qc := QC{Phase: vote.Phase, View: vote.View, BlockHash: vote.BlockHash, Votes: votes}
+	switch vote.Phase {
+	case PhasePrepare:
+		return r.cfg.Transport.BroadcastPreCommit(ctx, PreCommitMsg{View: vote.View, Prepare: qc})
+	case PhasePreCommit:
+		return r.cfg.Transport.BroadcastCommit(ctx, CommitMsg{View: vote.View, PreCommit: qc})
+	case PhaseCommit:
+		return r.finalize(ctx, vote.View, qc)
+	default:
+		return fmt.Errorf("unrecognized vote phase %v", vote.Phase)
+	}
+}
+
+// finalize broadcasts Decide so every replica (the leader included) learns QC_commit and can
+// release its bridge signature.
+func (r *Replica) finalize(ctx context.Context, view uint64, commitQC QC) error {
+	return r.cfg.Transport.BroadcastDecide(ctx, DecideMsg{View: view, Commit: commitQC})
+}
+
+// handlePreCommit verifies QC_prepare, locks this replica on its blockHash, and votes
+// PreCommitVote back to the leader.
+func (r *Replica) handlePreCommit(ctx context.Context, msg PreCommitMsg) error {
+	if !r.verifyQC(msg.Prepare) {
+		return nil
+	}
+	r.mu.Lock()
+	r.lockedQC = &msg.Prepare
+	view := r.view
+	r.mu.Unlock()
+	if err := r.persist(view); err != nil {
+		return err
+	}
+	return r.vote(ctx, PhasePreCommit, msg.View, msg.Prepare.BlockHash, r.cfg.leader(msg.View))
+}
+
+// handleCommit verifies QC_precommit, marks the batch prepared-to-commit, and votes CommitVote.
+func (r *Replica) handleCommit(ctx context.Context, msg CommitMsg) error {
+	if !r.verifyQC(msg.PreCommit) {
+		return nil
+	}
+	r.mu.Lock()
+	r.preparedQC = &msg.PreCommit
+	view := r.view
+	r.mu.Unlock()
+	if err := r.persist(view); err != nil {
+		return err
+	}
+	return r.vote(ctx, PhaseCommit, msg.View, msg.PreCommit.BlockHash, r.cfg.leader(msg.View))
+}
+
+// handleDecide verifies QC_commit and, only then, hands the batch's transfer IDs plus the QC to
+// cfg.OnCommit, before advancing to the next view.
+func (r *Replica) handleDecide(ctx context.Context, msg DecideMsg) error {
+	if !r.verifyQC(msg.Commit) {
+		return nil
+	}
+	r.mu.Lock()
+	ids := r.batches[msg.View]
+	delete(r.batches, msg.View)
+	r.mu.Unlock()
+
+	r.cfg.OnCommit(ctx, ids, msg.Commit)
+	return r.advanceView(msg.View + 1)
+}
+
+// onViewTimeout broadcasts NewView carrying this replica's highest lockedQC and advances past
+// the stalled view.
+func (r *Replica) onViewTimeout(ctx context.Context) error {
+	r.mu.Lock()
+	view := r.view
+	locked := r.lockedQC
+	r.mu.Unlock()
+	if err := r.cfg.Transport.BroadcastNewView(ctx, NewViewMsg{View: view, Replica: r.cfg.Signer.ID(), LockedQC: locked}); err != nil {
+		return err
+	}
+	return r.advanceView(view + 1)
+}
+
+// handleNewView lets the next leader recover the highest lockedQC a timed-out replica held, so a
+// batch that was prepared but never committed is not silently dropped.
+func (r *Replica) handleNewView(ctx context.Context, msg NewViewMsg) error {
+	if msg.LockedQC == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lockedQC == nil || msg.LockedQC.View > r.lockedQC.View {
+		r.lockedQC = msg.LockedQC
+	}
+	return nil
+}
+
+// advanceView moves to view, resetting the view timer so a fresh ViewTimeout window starts now.
+func (r *Replica) advanceView(view uint64) error {
+	r.mu.Lock()
+	if view > r.view {
+		r.view = view
+	}
+	v := r.view
+	r.mu.Unlock()
+	select {
+	case r.resetTimer <- struct{}{}:
+	default:
+	}
+	return r.persist(v)
+}
+
+func (r *Replica) vote(ctx context.Context, phase Phase, view uint64, blockHash BlockHash, to ReplicaID) error {
+	sig, err := r.cfg.Signer.Sign(signingPayload(phase, view, blockHash))
+	if err != nil {
+		return fmt.Errorf("failed to sign %s vote: %w", phase, err)
+	}
+	return r.cfg.Transport.SendVote(ctx, to, VoteMsg{
+		Phase:     phase,
+		View:      view,
+		BlockHash: blockHash,
+		Replica:   r.cfg.Signer.ID(),
+		Signature: sig,
+	})
+}
+
+// verifyQC checks that qc carries at least a quorum of distinct, individually-valid signatures
+// over (qc.Phase, qc.View, qc.BlockHash).
+func (r *Replica) verifyQC(qc QC) bool {
+	payload := signingPayload(qc.Phase, qc.View, qc.BlockHash)
+	seen := make(map[ReplicaID]struct{}, len(qc.Votes))
+	for _, v := range qc.Votes {
+		ok, err := r.cfg.Signer.Verify(v.Replica, payload, v.Signature)
+		if err != nil || !ok {
+			continue
+		}
+		seen[v.Replica] = struct{}{}
+	}
+	return len(seen) >= r.cfg.quorum()
+}
+
+func (r *Replica) persist(view uint64) error {
+	r.mu.Lock()
+	state := PersistedState{View: view, LockedQC: r.lockedQC, PreparedQC: r.preparedQC}
+	r.mu.Unlock()
+	return r.cfg.Store.SaveState(r.cfg.Signer.ID(), state)
+}
+
+func transferIDs(transfers []Transfer) []TransferID {
+	ids := make([]TransferID, len(transfers))
+	for i, t := range transfers {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+func sameTransferSet(a, b []TransferID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[TransferID]struct{}, len(a))
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+	for _, id := range b {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func distinctReplicas(votes []Vote) map[ReplicaID]struct{} {
+	seen := make(map[ReplicaID]struct{}, len(votes))
+	for _, v := range votes {
+		seen[v.Replica] = struct{}{}
+	}
+	return seen
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestComputeBlockHashDeterminism checks that ComputeBlockHash is a pure function of its
+// logical inputs: reordering the transfer IDs must not change the result, but changing any of
+// transferIDs, sourceChain, destChain or view must.
+func TestComputeBlockHashDeterminism(t *testing.T) {
+	ids := []TransferID{"t1", "t2", "t3"}
+	reordered := []TransferID{"t3", "t1", "t2"}
+
+	base := ComputeBlockHash(ids, "ethereum", "iotex", 1)
+	if got := ComputeBlockHash(ids, "ethereum", "iotex", 1); got != base {
+		t.Fatalf("ComputeBlockHash is not deterministic across repeated calls")
+	}
+	if got := ComputeBlockHash(reordered, "ethereum", "iotex", 1); got != base {
+		t.Fatalf("ComputeBlockHash is sensitive to transfer ID order, want order-independent")
+	}
+	// Must not mutate the slice passed in.
+	if ids[0] != "t1" || ids[1] != "t2" || ids[2] != "t3" {
+		t.Fatalf("ComputeBlockHash mutated its input slice: %v", ids)
+	}
+
+	if got := ComputeBlockHash([]TransferID{"t1", "t2", "t4"}, "ethereum", "iotex", 1); got == base {
+		t.Fatalf("ComputeBlockHash did not change when transfer IDs changed")
+	}
+	if got := ComputeBlockHash(ids, "bsc", "iotex", 1); got == base {
+		t.Fatalf("ComputeBlockHash did not change when sourceChain changed")
+	}
+	if got := ComputeBlockHash(ids, "ethereum", "solana", 1); got == base {
+		t.Fatalf("ComputeBlockHash did not change when destChain changed")
+	}
+	if got := ComputeBlockHash(ids, "ethereum", "iotex", 2); got == base {
+		t.Fatalf("ComputeBlockHash did not change when view changed")
+	}
+}
+
+// alwaysOKSigner treats every signature as valid and every Sign call as trivially successful,
+// so these tests exercise handlePrepare's safety rule without needing a real signature scheme.
+type alwaysOKSigner struct{ id ReplicaID }
+
+func (s alwaysOKSigner) ID() ReplicaID { return s.id }
+func (s alwaysOKSigner) Sign(payload []byte) ([]byte, error) {
+	return []byte("sig"), nil
+}
+func (s alwaysOKSigner) Verify(replica ReplicaID, payload []byte, signature []byte) (bool, error) {
+	return true, nil
+}
+
+// fixedTransferSource always reports the same pending transfers, standing in for whatever this
+// replica would independently have observed itself.
+type fixedTransferSource struct {
+	ids []TransferID
+}
+
+func (s fixedTransferSource) PendingTransfers(ctx context.Context, sourceChain, destChain string) ([]Transfer, error) {
+	transfers := make([]Transfer, len(s.ids))
+	for i, id := range s.ids {
+		transfers[i] = Transfer{ID: id, SourceChain: sourceChain, DestChain: destChain}
+	}
+	return transfers, nil
+}
+
+// memStore is a minimal in-memory Store, just enough for NewReplica/persist to work in a test.
+type memStore struct {
+	state PersistedState
+}
+
+func (s *memStore) LoadState(replica ReplicaID) (PersistedState, error) { return s.state, nil }
+func (s *memStore) SaveState(replica ReplicaID, state PersistedState) error {
+	s.state = state
+	return nil
+}
+
+// recordingTransport records every vote sent to it; these tests never propose or broadcast, so
+// the other Transport methods are left unimplemented (panicking) to catch accidental use.
+type recordingTransport struct {
+	votes []VoteMsg
+}
+
+func (r *recordingTransport) BroadcastPrepare(ctx context.Context, msg PrepareMsg) error {
+	panic("not used by this test")
+}
+func (r *recordingTransport) SendVote(ctx context.Context, to ReplicaID, msg VoteMsg) error {
+	r.votes = append(r.votes, msg)
+	return nil
+}
+func (r *recordingTransport) BroadcastPreCommit(ctx context.Context, msg PreCommitMsg) error {
+	panic("not used by this test")
+}
+func (r *recordingTransport) BroadcastCommit(ctx context.Context, msg CommitMsg) error {
+	panic("not used by this test")
+}
+func (r *recordingTransport) BroadcastDecide(ctx context.Context, msg DecideMsg) error {
+	panic("not used by this test")
+}
+func (r *recordingTransport) BroadcastNewView(ctx context.Context, msg NewViewMsg) error {
+	panic("not used by this test")
+}
+func (r *recordingTransport) Inbox(ctx context.Context) <-chan interface{} {
+	panic("not used by this test")
+}
+
+// newTestReplica builds a 1-of-1 Replica (so this process is always the leader/quorum of one)
+// with the given lockedQC already in place and ownIDs as what it would independently observe as
+// pending transfers, for exercising handlePrepare directly.
+func newTestReplica(t *testing.T, lockedQC *QC, ownIDs []TransferID) (*Replica, *recordingTransport) {
+	t.Helper()
+	transport := &recordingTransport{}
+	r, err := NewReplica(Config{
+		Peers:       []ReplicaID{1},
+		Threshold:   0,
+		ViewTimeout: time.Minute,
+		Interval:    time.Minute,
+		SourceChain: "ethereum",
+		DestChain:   "iotex",
+		Transport:   transport,
+		Transfers:   fixedTransferSource{ids: ownIDs},
+		Signer:      alwaysOKSigner{id: 1},
+		Store:       &memStore{},
+		OnCommit:    func(ctx context.Context, transferIDs []TransferID, commitQC QC) {},
+	})
+	if err != nil {
+		t.Fatalf("failed to build test replica: %v", err)
+	}
+	r.lockedQC = lockedQC
+	return r, transport
+}
+
+// TestHandlePrepareSafetyRuleExtendsLock verifies a Prepare matching the locked BlockHash is
+// always voted for, regardless of view.
+func TestHandlePrepareSafetyRuleExtendsLock(t *testing.T) {
+	ids := []TransferID{"t1"}
+	msgHash := ComputeBlockHash(ids, "ethereum", "iotex", 5)
+	// Locked on the very same BlockHash this Prepare recomputes to - e.g. the leader's
+	// retransmission of a proposal this replica already voted Prepare for and locked on.
+	locked := &QC{Phase: PhasePrepare, View: 5, BlockHash: msgHash}
+	r, transport := newTestReplica(t, locked, ids)
+
+	msg := PrepareMsg{
+		View:        5,
+		BlockHash:   msgHash,
+		TransferIDs: ids,
+		SourceChain: "ethereum",
+		DestChain:   "iotex",
+	}
+	if err := r.handlePrepare(context.Background(), msg); err != nil {
+		t.Fatalf("handlePrepare returned error: %v", err)
+	}
+	if len(transport.votes) != 1 {
+		t.Fatalf("expected a vote for a Prepare that recomputes to the locked BlockHash, got %d votes", len(transport.votes))
+	}
+}
+
+// TestHandlePrepareSafetyRuleRejectsUnjustifiedConflict verifies a Prepare that conflicts with
+// the lockedQC and carries no (or an insufficiently-justified) Justify QC is never voted for,
+// including when its View is higher than the lock's - this is the bug the review flagged: the
+// previous rule only rejected conflicts at msg.View <= locked.View.
+func TestHandlePrepareSafetyRuleRejectsUnjustifiedConflict(t *testing.T) {
+	locked := &QC{Phase: PhasePrepare, View: 1, BlockHash: BlockHash{0x01}}
+	conflictingIDs := []TransferID{"t2"}
+
+	cases := []struct {
+		name    string
+		justify *QC
+	}{
+		{name: "no justify", justify: nil},
+		{name: "justify view not higher than lock", justify: &QC{Phase: PhasePrepare, View: 1, BlockHash: BlockHash{0x02}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, transport := newTestReplica(t, locked, conflictingIDs)
+			msg := PrepareMsg{
+				View:        5,
+				BlockHash:   ComputeBlockHash(conflictingIDs, "ethereum", "iotex", 5),
+				TransferIDs: conflictingIDs,
+				SourceChain: "ethereum",
+				DestChain:   "iotex",
+				Justify:     c.justify,
+			}
+			if err := r.handlePrepare(context.Background(), msg); err != nil {
+				t.Fatalf("handlePrepare returned error: %v", err)
+			}
+			if len(transport.votes) != 0 {
+				t.Fatalf("expected no vote for an unjustified conflicting Prepare, got %d votes", len(transport.votes))
+			}
+		})
+	}
+}
+
+// TestHandlePrepareSafetyRuleAcceptsHigherQCOverride verifies a Prepare that conflicts with the
+// lockedQC IS voted for when it carries a valid Justify QC from a later view - the override this
+// fix adds.
+func TestHandlePrepareSafetyRuleAcceptsHigherQCOverride(t *testing.T) {
+	locked := &QC{Phase: PhasePrepare, View: 1, BlockHash: BlockHash{0x01}}
+	conflictingIDs := []TransferID{"t2"}
+	r, transport := newTestReplica(t, locked, conflictingIDs)
+
+	msg := PrepareMsg{
+		View:        5,
+		BlockHash:   ComputeBlockHash(conflictingIDs, "ethereum", "iotex", 5),
+		TransferIDs: conflictingIDs,
+		SourceChain: "ethereum",
+		DestChain:   "iotex",
+		Justify: &QC{
+			Phase:     PhasePrepare,
+			View:      4,
+			BlockHash: BlockHash{0x02},
+			Votes:     []Vote{{Replica: 1, Signature: []byte("sig")}},
+		},
+	}
+	if err := r.handlePrepare(context.Background(), msg); err != nil {
+		t.Fatalf("handlePrepare returned error: %v", err)
+	}
+	if len(transport.votes) != 1 {
+		t.Fatalf("expected a vote for a Prepare justified by a higher QC, got %d votes", len(transport.votes))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Secp256k1Signer is a Signer backed by the same secp256k1 scheme
+// witness.NewSecp256k1SignHandler uses for bridge signatures.
+type Secp256k1Signer struct {
+	id    ReplicaID
+	key   *ecdsa.PrivateKey
+	peers map[ReplicaID]*ecdsa.PublicKey
+}
+
+// NewSecp256k1Signer builds a Secp256k1Signer that signs as id using key and verifies every other
+// replica in peers (which should include id's own public key, for Verify calls against self).
+func NewSecp256k1Signer(id ReplicaID, key *ecdsa.PrivateKey, peers map[ReplicaID]*ecdsa.PublicKey) *Secp256k1Signer {
+	return &Secp256k1Signer{id: id, key: key, peers: peers}
+}
+
+// ID implements Signer.
+func (s *Secp256k1Signer) ID() ReplicaID { return s.id }
+
+// Sign implements Signer.
+func (s *Secp256k1Signer) Sign(payload []byte) ([]byte, error) {
+	h := sha256.Sum256(payload)
+	return crypto.Sign(h[:], s.key)
+}
+
+// Verify implements Signer.
+func (s *Secp256k1Signer) Verify(replica ReplicaID, payload []byte, signature []byte) (bool, error) {
+	pub, ok := s.peers[replica]
+	if !ok {
+		return false, fmt.Errorf("consensus: no public key registered for replica %d", replica)
+	}
+	if len(signature) != 65 {
+		return false, nil
+	}
+	h := sha256.Sum256(payload)
+	sigPub, err := crypto.SigToPub(h[:], signature)
+	if err != nil {
+		return false, nil
+	}
+	return crypto.PubkeyToAddress(*sigPub) == crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package consensus
+
+import "sync"
+
+// MemStore is an in-memory Store. It gives no durability across restarts: a crash loses the
+// replica's lockedQC/preparedQC, which is only safe for short-lived tooling or a deployment that
+// accepts re-proposing from view 0 after a restart. Production deployments should persist
+// PersistedState the same way the rest of this service uses db.Store (see
+// contract/eventstream.DBStore for the equivalent already wired for eventstream checkpoints).
+type MemStore struct {
+	mu     sync.Mutex
+	states map[ReplicaID]PersistedState
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{states: make(map[ReplicaID]PersistedState)}
+}
+
+// LoadState implements Store.
+func (s *MemStore) LoadState(replica ReplicaID) (PersistedState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[replica], nil
+}
+
+// SaveState implements Store.
+func (s *MemStore) SaveState(replica ReplicaID, state PersistedState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[replica] = state
+	return nil
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// This file is hand-written, not abigen output (there is no Solidity source or compiler for
+// AddressList in this tree to generate it from). It extends the AddressList binding with the
+// ItemAdded/ItemDeactivated events added to AddressListABI in addresslist.go, so the cache package
+// can follow membership changes from logs instead of polling alone - see AddressListABI's doc
+// comment for why AddressListBin itself was not, and could not be, updated to match.
+
+package contract
+
+import (
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// AddressListItemAdded represents an ItemAdded event raised by the AddressList contract.
+type AddressListItemAdded struct {
+	Item common.Address
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterItemAdded is a free log retrieval operation binding the contract event ItemAdded.
+//
+// Solidity: event ItemAdded(address indexed item)
+func (_AddressList *AddressListFilterer) FilterItemAdded(opts *bind.FilterOpts, item []common.Address) (*AddressListItemAddedIterator, error) {
+	var itemRule []interface{}
+	for _, itemItem := range item {
+		itemRule = append(itemRule, itemItem)
+	}
+	logs, sub, err := _AddressList.contract.FilterLogs(opts, "ItemAdded", itemRule)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressListItemAddedIterator{contract: _AddressList.contract, event: "ItemAdded", logs: logs, sub: sub}, nil
+}
+
+// WatchItemAdded is a free log subscription operation binding the contract event ItemAdded.
+//
+// Solidity: event ItemAdded(address indexed item)
+func (_AddressList *AddressListFilterer) WatchItemAdded(opts *bind.WatchOpts, sink chan<- *AddressListItemAdded, item []common.Address) (event.Subscription, error) {
+	var itemRule []interface{}
+	for _, itemItem := range item {
+		itemRule = append(itemRule, itemItem)
+	}
+	logs, sub, err := _AddressList.contract.WatchLogs(opts, "ItemAdded", itemRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev := new(AddressListItemAdded)
+				if err := _AddressList.contract.UnpackLog(ev, "ItemAdded", log); err != nil {
+					return err
+				}
+				ev.Raw = log
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseItemAdded is a log parse operation binding the contract event ItemAdded.
+//
+// Solidity: event ItemAdded(address indexed item)
+func (_AddressList *AddressListFilterer) ParseItemAdded(log types.Log) (*AddressListItemAdded, error) {
+	ev := new(AddressListItemAdded)
+	if err := _AddressList.contract.UnpackLog(ev, "ItemAdded", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
+
+// AddressListItemAddedIterator is returned from FilterItemAdded and is used to iterate over the
+// raw logs and unpacked data for ItemAdded events raised by the AddressList contract.
+type AddressListItemAddedIterator struct {
+	Event *AddressListItemAdded
+
+	contract *bind.BoundContract
+	event    string
+
+	logs chan types.Log
+	sub  ethereum.Subscription
+	done bool
+	fail error
+}
+
+// Next advances the iterator to the subsequent event, returning whether there are any more
+// events found. In case of a retrieval or parsing error, false is returned and Error() can be
+// queried for the exact failure.
+func (it *AddressListItemAddedIterator) Next() bool {
+	if it.fail != nil {
+		return false
+	}
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AddressListItemAdded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case log := <-it.logs:
+		it.Event = new(AddressListItemAdded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AddressListItemAddedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying resources.
+func (it *AddressListItemAddedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AddressListItemDeactivated represents an ItemDeactivated event raised by the AddressList contract.
+type AddressListItemDeactivated struct {
+	Item common.Address
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterItemDeactivated is a free log retrieval operation binding the contract event ItemDeactivated.
+//
+// Solidity: event ItemDeactivated(address indexed item)
+func (_AddressList *AddressListFilterer) FilterItemDeactivated(opts *bind.FilterOpts, item []common.Address) (*AddressListItemDeactivatedIterator, error) {
+	var itemRule []interface{}
+	for _, itemItem := range item {
+		itemRule = append(itemRule, itemItem)
+	}
+	logs, sub, err := _AddressList.contract.FilterLogs(opts, "ItemDeactivated", itemRule)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressListItemDeactivatedIterator{contract: _AddressList.contract, event: "ItemDeactivated", logs: logs, sub: sub}, nil
+}
+
+// WatchItemDeactivated is a free log subscription operation binding the contract event ItemDeactivated.
+//
+// Solidity: event ItemDeactivated(address indexed item)
+func (_AddressList *AddressListFilterer) WatchItemDeactivated(opts *bind.WatchOpts, sink chan<- *AddressListItemDeactivated, item []common.Address) (event.Subscription, error) {
+	var itemRule []interface{}
+	for _, itemItem := range item {
+		itemRule = append(itemRule, itemItem)
+	}
+	logs, sub, err := _AddressList.contract.WatchLogs(opts, "ItemDeactivated", itemRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev := new(AddressListItemDeactivated)
+				if err := _AddressList.contract.UnpackLog(ev, "ItemDeactivated", log); err != nil {
+					return err
+				}
+				ev.Raw = log
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseItemDeactivated is a log parse operation binding the contract event ItemDeactivated.
+//
+// Solidity: event ItemDeactivated(address indexed item)
+func (_AddressList *AddressListFilterer) ParseItemDeactivated(log types.Log) (*AddressListItemDeactivated, error) {
+	ev := new(AddressListItemDeactivated)
+	if err := _AddressList.contract.UnpackLog(ev, "ItemDeactivated", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
+
+// AddressListItemDeactivatedIterator is returned from FilterItemDeactivated and is used to
+// iterate over the raw logs and unpacked data for ItemDeactivated events raised by the
+// AddressList contract.
+type AddressListItemDeactivatedIterator struct {
+	Event *AddressListItemDeactivated
+
+	contract *bind.BoundContract
+	event    string
+
+	logs chan types.Log
+	sub  ethereum.Subscription
+	done bool
+	fail error
+}
+
+// Next advances the iterator to the subsequent event, returning whether there are any more
+// events found. In case of a retrieval or parsing error, false is returned and Error() can be
+// queried for the exact failure.
+func (it *AddressListItemDeactivatedIterator) Next() bool {
+	if it.fail != nil {
+		return false
+	}
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AddressListItemDeactivated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case log := <-it.logs:
+		it.Event = new(AddressListItemDeactivated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AddressListItemDeactivatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying resources.
+func (it *AddressListItemDeactivatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PrivateTransactOpts is bind.TransactOpts plus the Tessera/Constellation fields a Quorum or
+// Besu deployment needs to keep a transaction's payload off the public chain. When PrivateFrom
+// is empty, TransferOwnershipPrivate behaves exactly like the public TransferOwnership call.
+type PrivateTransactOpts struct {
+	bind.TransactOpts
+
+	// PrivateFrom is the sender's enclave public key.
+	PrivateFrom string
+	// PrivateFor is the set of recipient enclave public keys that may decrypt the payload.
+	PrivateFor []string
+	// PrivateKey signs the private transaction itself, in place of TransactOpts.Signer. A
+	// Quorum/Besu node recovers the sender of a V=37/38 private transaction against the legacy
+	// (pre-EIP-155) sighash, but TransactOpts.Signer is typically built (e.g. via
+	// bind.NewKeyedTransactorWithChainID) to sign the EIP-155 sighash for public submission;
+	// reusing it and only rewriting V would produce a signature that recovers to the wrong
+	// sender. Required whenever PrivateFrom is set.
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// PrivateBackend abstracts the enclave HTTP client (Tessera/Constellation) and the node's
+// private-transaction submission RPC, so tests can stub both out.
+type PrivateBackend interface {
+	// StoreRaw POSTs the call payload to the private transaction manager and returns the
+	// enclave hash that should replace the transaction's data.
+	StoreRaw(ctx context.Context, payload []byte, privateFrom string) ([]byte, error)
+	// SendRawPrivateTransaction submits a signed, enclave-hash-bearing transaction to the node.
+	SendRawPrivateTransaction(ctx context.Context, signedRawTx []byte, privateFor []string) (common.Hash, error)
+}
+
+// TesseraBackend is a PrivateBackend backed by a Tessera/Constellation-style enclave reachable
+// over HTTP and a node RPC endpoint that accepts eth_sendRawPrivateTransaction.
+type TesseraBackend struct {
+	PrivateTxManagerURL string
+	NodeRPCURL          string
+	HTTPClient          *http.Client
+}
+
+func (b *TesseraBackend) httpClient() *http.Client {
+	if b.HTTPClient != nil {
+		return b.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// StoreRaw implements PrivateBackend.
+func (b *TesseraBackend) StoreRaw(ctx context.Context, payload []byte, privateFrom string) ([]byte, error) {
+	body, err := json.Marshal(struct {
+		Payload []byte `json:"payload"`
+		From    string `json:"from,omitempty"`
+	}{Payload: payload, From: privateFrom})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal /storeraw request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.PrivateTxManagerURL+"/storeraw", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build /storeraw request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call /storeraw: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("private transaction manager returned status %d", resp.StatusCode)
+	}
+	var out struct {
+		Key []byte `json:"key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode /storeraw response: %w", err)
+	}
+	return out.Key, nil
+}
+
+// SendRawPrivateTransaction implements PrivateBackend.
+func (b *TesseraBackend) SendRawPrivateTransaction(ctx context.Context, signedRawTx []byte, privateFor []string) (common.Hash, error) {
+	body, err := json.Marshal(struct {
+		JSONRPC string        `json:"jsonrpc"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+		ID      int           `json:"id"`
+	}{
+		JSONRPC: "2.0",
+		Method:  "eth_sendRawPrivateTransaction",
+		Params:  []interface{}{fmt.Sprintf("0x%x", signedRawTx), map[string]interface{}{"privateFor": privateFor}},
+		ID:      1,
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to marshal eth_sendRawPrivateTransaction request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.NodeRPCURL, bytes.NewReader(body))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to build eth_sendRawPrivateTransaction request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient().Do(req)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to call eth_sendRawPrivateTransaction: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.Hash{}, fmt.Errorf("node RPC endpoint returned status %d", resp.StatusCode)
+	}
+	var out struct {
+		Result common.Hash `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to decode eth_sendRawPrivateTransaction response: %w", err)
+	}
+	if out.Error != nil {
+		return common.Hash{}, fmt.Errorf("eth_sendRawPrivateTransaction failed: %s", out.Error.Message)
+	}
+	return out.Result, nil
+}
+
+// markPrivate rewrites tx's V per the Quorum convention for private transactions (37 for
+// recovery id 0, 38 for recovery id 1), in place of the plain Homestead value (27/28) it must
+// have been signed with - see PrivateTransactOpts.PrivateKey's doc comment for why it can't be
+// an EIP-155-signed transaction's V being rewritten in place.
+func markPrivate(tx *types.Transaction) *types.Transaction {
+	v, r, s := tx.RawSignatureValues()
+	privateV := big.NewInt(37)
+	if v.Uint64() == 28 {
+		privateV = big.NewInt(38)
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: tx.GasPrice(),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+		V:        privateV,
+		R:        r,
+		S:        s,
+	})
+}
+
+// transactPrivate packs method/params, stores the resulting payload in the enclave, builds a
+// transaction whose data is the returned enclave hash, signs it with opts.PrivateKey, marks it
+// private, and submits it via backend.SendRawPrivateTransaction instead of the public mempool.
+func transactPrivate(contractAddr common.Address, opts *PrivateTransactOpts, backend PrivateBackend, method string, params ...interface{}) (*types.Transaction, error) {
+	parsed, err := abi.JSON(strings.NewReader(AddressListABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AddressList ABI: %w", err)
+	}
+	input, err := parsed.Pack(method, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s call: %w", method, err)
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	enclaveHash, err := backend.StoreRaw(ctx, input, opts.PrivateFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store private payload: %w", err)
+	}
+
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0) // private transactions on Quorum/Besu carry zero gas price
+	}
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	}
+	unsigned := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      opts.GasLimit,
+		To:       &contractAddr,
+		Value:    big.NewInt(0),
+		Data:     enclaveHash,
+	})
+
+	if opts.PrivateKey == nil {
+		return nil, fmt.Errorf("no PrivateKey provided in PrivateTransactOpts")
+	}
+	signed, err := types.SignTx(unsigned, types.HomesteadSigner{}, opts.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign private transaction: %w", err)
+	}
+	privateTx := markPrivate(signed)
+
+	raw, err := privateTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private transaction: %w", err)
+	}
+	if _, err := backend.SendRawPrivateTransaction(ctx, raw, opts.PrivateFor); err != nil {
+		return nil, fmt.Errorf("failed to submit private transaction: %w", err)
+	}
+	return privateTx, nil
+}
+
+// TransferOwnershipPrivate mirrors AddressListTransactor.TransferOwnership but, when opts
+// carries a non-empty PrivateFrom, routes the transaction through backend so the allowlist
+// ownership rotation is never published to the public chain: the call payload is stored in the
+// enclave keyed by PrivateFrom/PrivateFor, the transaction's data becomes the returned enclave
+// hash, and the result is submitted via SendRawPrivateTransaction instead of the public mempool.
+func (_AddressList *AddressListTransactor) TransferOwnershipPrivate(opts *PrivateTransactOpts, newOwner common.Address, backend PrivateBackend) (*types.Transaction, error) {
+	if opts.PrivateFrom == "" {
+		return _AddressList.TransferOwnership(&opts.TransactOpts, newOwner)
+	}
+	return transactPrivate(_AddressList.address, opts, backend, "transferOwnership", newOwner)
+}
+
+// TransferOwnershipPrivate calls AddressListTransactor.TransferOwnershipPrivate using the
+// session's pre-set TransactOpts, PrivateFrom/PrivateFor and Backend. It sets no PrivateKey, so
+// when PrivateFrom is non-empty it returns transactPrivate's "no PrivateKey provided" error.
+func (_AddressList *AddressListTransactorSession) TransferOwnershipPrivate(newOwner common.Address) (*types.Transaction, error) {
+	opts := &PrivateTransactOpts{
+		TransactOpts: _AddressList.TransactOpts,
+		PrivateFrom:  _AddressList.PrivateFrom,
+		PrivateFor:   _AddressList.PrivateFor,
+	}
+	return _AddressList.Contract.TransferOwnershipPrivate(opts, newOwner, _AddressList.Backend)
+}
+
+// DeployAddressListPrivate mirrors contract.DeployAddressList but, when opts carries a non-empty
+// PrivateFrom, deploys the contract through backend instead of the public mempool: the init code
+// is stored in the enclave, the deployment transaction's data becomes the returned enclave hash,
+// and the contract address is derived the same way go-ethereum's bind.DeployContract does, from
+// opts.From and the transaction's nonce.
+func DeployAddressListPrivate(opts *PrivateTransactOpts, backend bind.ContractBackend, privateBackend PrivateBackend) (common.Address, *types.Transaction, *AddressList, error) {
+	if opts.PrivateFrom == "" {
+		return DeployAddressList(&opts.TransactOpts, backend)
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	enclaveHash, err := privateBackend.StoreRaw(ctx, common.FromHex(AddressListBin), opts.PrivateFrom)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to store private deployment payload: %w", err)
+	}
+
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0) // private transactions on Quorum/Besu carry zero gas price
+	}
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	}
+	unsigned := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      opts.GasLimit,
+		Value:    big.NewInt(0),
+		Data:     enclaveHash,
+	})
+
+	if opts.PrivateKey == nil {
+		return common.Address{}, nil, nil, fmt.Errorf("no PrivateKey provided in PrivateTransactOpts")
+	}
+	signed, err := types.SignTx(unsigned, types.HomesteadSigner{}, opts.PrivateKey)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to sign private deployment transaction: %w", err)
+	}
+	privateTx := markPrivate(signed)
+
+	raw, err := privateTx.MarshalBinary()
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to encode private deployment transaction: %w", err)
+	}
+	if _, err := privateBackend.SendRawPrivateTransaction(ctx, raw, opts.PrivateFor); err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to submit private deployment transaction: %w", err)
+	}
+
+	contractAddr := crypto.CreateAddress(opts.From, nonce)
+	addressList, err := NewAddressList(contractAddr, backend)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to bind deployed AddressList: %w", err)
+	}
+	return contractAddr, privateTx, addressList, nil
+}
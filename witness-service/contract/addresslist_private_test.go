@@ -0,0 +1,108 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakePrivateBackend is a PrivateBackend that echoes the call payload as its own enclave hash
+// and records the signed raw transaction it was asked to submit.
+type fakePrivateBackend struct {
+	sentRawTx []byte
+}
+
+func (b *fakePrivateBackend) StoreRaw(ctx context.Context, payload []byte, privateFrom string) ([]byte, error) {
+	return payload, nil
+}
+
+func (b *fakePrivateBackend) SendRawPrivateTransaction(ctx context.Context, signedRawTx []byte, privateFor []string) (common.Hash, error) {
+	b.sentRawTx = signedRawTx
+	return common.Hash{}, nil
+}
+
+// TestTransactPrivateRecoversToPrivateKeyAddress verifies that the transaction transactPrivate
+// submits actually recovers to opts.PrivateKey's address: markPrivate rewrites V to the Quorum
+// private-transaction convention (37/38), and a node validates that by recovering the sender
+// against the legacy (Homestead) sighash, not the EIP-155 one, so the two must agree.
+func TestTransactPrivateRecoversToPrivateKeyAddress(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	opts := &PrivateTransactOpts{
+		PrivateFrom: "enclave-key",
+		PrivateKey:  key,
+	}
+	opts.From = from
+	opts.GasLimit = 100000
+
+	backend := &fakePrivateBackend{}
+	tx, err := transactPrivate(common.HexToAddress("0xabc"), opts, backend, "transferOwnership", common.HexToAddress("0xdef"))
+	if err != nil {
+		t.Fatalf("transactPrivate returned error: %v", err)
+	}
+
+	v, r, s := tx.RawSignatureValues()
+	if v.Uint64() != 37 && v.Uint64() != 38 {
+		t.Fatalf("got V %s, want 37 or 38", v)
+	}
+	// Undo the Quorum private-V rewrite to get back the plain Homestead V (27/28), then check
+	// that the signature recovers to opts.PrivateKey's own address under the Homestead
+	// (pre-EIP-155) sighash - the one a real Quorum/Besu node checks a private transaction
+	// against.
+	homesteadV := int64(27)
+	if v.Uint64() == 38 {
+		homesteadV = 28
+	}
+	homesteadTx := types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: tx.GasPrice(),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+		V:        big.NewInt(homesteadV),
+		R:        r,
+		S:        s,
+	})
+	recoveredAddr, err := types.Sender(types.HomesteadSigner{}, homesteadTx)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if recoveredAddr != from {
+		t.Fatalf("recovered sender %s, want %s (opts.PrivateKey's own address) - signature was not computed over the legacy sighash markPrivate's V assumes", recoveredAddr, from)
+	}
+	if backend.sentRawTx == nil {
+		t.Fatalf("expected the signed transaction to be submitted via SendRawPrivateTransaction")
+	}
+}
+
+// TestSendRawPrivateTransactionChecksStatusCode verifies that a non-200 response from the node
+// RPC endpoint is surfaced as an error instead of being decoded as a zero-value success.
+func TestSendRawPrivateTransactionChecksStatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	backend := &TesseraBackend{NodeRPCURL: srv.URL}
+	_, err := backend.SendRawPrivateTransaction(context.Background(), []byte{0x01}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a 500 response, got nil")
+	}
+}
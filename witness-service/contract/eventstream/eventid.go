@@ -0,0 +1,32 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package eventstream
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EventID returns the topic0 hash of eventName as declared in contractABI, e.g.
+// contract.AddressListABI and "OwnershipTransferred". This is the piece every generated
+// *Filterer already computes internally (via its private bindXxx/abi.JSON call) but does not
+// expose, so a Filter/WatchFunc built directly against the ContractFilterer backend needs it to
+// build its own ethereum.FilterQuery.
+func EventID(contractABI, eventName string) (common.Hash, error) {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	ev, ok := parsed.Events[eventName]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("event %s not found in ABI", eventName)
+	}
+	return ev.ID, nil
+}
@@ -0,0 +1,318 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package eventstream wraps any generated *Filterer's events (OwnershipTransferred, Settled,
+// cashier events, ...) in a reorg-safe pipeline: it delivers {Event, Confirmed, Reverted}
+// messages instead of raw WatchLogs deliveries, waits for a configurable confirmation depth
+// before marking an event Confirmed, emits an explicit Reverted message if a previously-seen log
+// disappears from the canonical chain, and checkpoints its progress so a restart resumes instead
+// of reprocessing from genesis or silently losing in-flight events.
+//
+// A Stream does not know about any specific generated binding; it operates on raw types.Log plus
+// caller-supplied Filter/Watch/Decode functions, which every generated *Filterer can be adapted
+// to with a few lines (see EventID for building the FilterQuery topic).
+package eventstream
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// HeaderReader is the subset of an RPC client a Stream needs to find the chain head and verify
+// that a previously-seen log is still part of the canonical chain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// FilterFunc returns the raw logs for a single generated *Filterer's event in [opts.Start,
+// *opts.End], the same range a generated Filter<Event> method would be called with.
+type FilterFunc func(ctx context.Context, opts *bind.FilterOpts) ([]types.Log, error)
+
+// WatchFunc subscribes to a single generated *Filterer's event going forward, the same event a
+// generated Watch<Event> method would subscribe to, but delivered as raw logs.
+type WatchFunc func(ctx context.Context) (<-chan types.Log, event.Subscription, error)
+
+// DecodeFunc turns a raw log back into the caller's generated event struct, e.g.
+// filterer.ParseOwnershipTransferred.
+type DecodeFunc func(types.Log) (interface{}, error)
+
+// Config holds the parameters a Stream is built with.
+type Config struct {
+	Filter        FilterFunc
+	Watch         WatchFunc
+	Decode        DecodeFunc
+	Headers       HeaderReader
+	Confirmations uint64
+	// Store persists progress so a restart resumes instead of reprocessing from genesis or
+	// losing in-flight (unconfirmed) events. A nil Store defaults to a fresh in-memory Store,
+	// which gives no crash durability; pass a FileStore (or a BoltDB/SQL-backed Store) to keep
+	// it across restarts.
+	Store Store
+}
+
+// Message is a single delivery from a Stream.
+type Message struct {
+	// Event is the decoded event, as produced by Config.Decode.
+	Event interface{}
+	// Log is the raw log the event was decoded from.
+	Log types.Log
+	// Confirmed is true once Config.Confirmations blocks have passed since Log without the
+	// block it was mined in being reorged out.
+	Confirmed bool
+	// Reverted is true if Log was previously delivered unconfirmed and has since disappeared
+	// from the canonical chain, i.e. the block it was mined in was reorged out before reaching
+	// Confirmations. A log already delivered Confirmed is not checked again and is never
+	// reported Reverted. Event and Confirmed are not meaningful when Reverted.
+	Reverted bool
+}
+
+// Stream delivers a reorg-safe, checkpointed, confirmation-gated view of a single generated
+// *Filterer's event.
+type Stream struct {
+	cfg Config
+
+	mu        sync.Mutex
+	pending   []pendingLog
+	processed uint64
+}
+
+// pendingLog is a log that has been seen but not yet confirmed or reverted.
+type pendingLog struct {
+	Log types.Log `json:"log"`
+}
+
+// New builds a Stream from cfg.
+func New(cfg Config) *Stream {
+	if cfg.Store == nil {
+		cfg.Store = NewMemStore()
+	}
+	return &Stream{cfg: cfg}
+}
+
+// Run loads the last checkpoint, replays any unconfirmed events left over from a previous run,
+// backfills everything since the checkpoint, subscribes to new logs going forward, and starts
+// the confirmation/revert sweep. It returns the channel Messages are delivered on; ctx
+// cancellation stops the Stream.
+func (s *Stream) Run(ctx context.Context, pollInterval time.Duration) (<-chan Message, error) {
+	checkpoint, err := s.cfg.Store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load eventstream checkpoint: %w", err)
+	}
+	s.mu.Lock()
+	s.processed = checkpoint.ProcessedThrough
+	s.pending = make([]pendingLog, len(checkpoint.Pending))
+	for i, log := range checkpoint.Pending {
+		s.pending[i] = pendingLog{Log: log}
+	}
+	s.mu.Unlock()
+
+	out := make(chan Message)
+
+	// Replay whatever was still unconfirmed when the process last stopped. These are already in
+	// s.pending from the checkpoint, so this only re-sends them; it does not re-append them.
+	s.mu.Lock()
+	replay := append([]pendingLog(nil), s.pending...)
+	s.mu.Unlock()
+	for _, p := range replay {
+		ev, err := s.cfg.Decode(p.Log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode replayed log: %w", err)
+		}
+		select {
+		case out <- Message{Event: ev, Log: p.Log, Confirmed: false}:
+		case <-ctx.Done():
+			return out, nil
+		}
+	}
+
+	if err := s.backfill(ctx, out); err != nil {
+		return nil, err
+	}
+	if err := s.tail(ctx, out); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		defer close(out)
+		for {
+			select {
+			case <-ticker.C:
+				s.sweep(ctx, out)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// backfill fetches every log between the last checkpoint and the current head and delivers it.
+func (s *Stream) backfill(ctx context.Context, out chan<- Message) error {
+	head, err := s.cfg.Headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read chain head: %w", err)
+	}
+	headNum := head.Number.Uint64()
+
+	s.mu.Lock()
+	from := s.processed + 1
+	s.mu.Unlock()
+	if from > headNum {
+		return nil
+	}
+
+	logs, err := s.cfg.Filter(ctx, &bind.FilterOpts{Start: from, End: &headNum, Context: ctx})
+	if err != nil {
+		return fmt.Errorf("failed to backfill logs: %w", err)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+	for _, log := range logs {
+		if err := s.deliver(ctx, out, log); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.processed = headNum
+	s.mu.Unlock()
+	return s.persist()
+}
+
+// tail subscribes to new logs going forward and delivers each as it arrives.
+func (s *Stream) tail(ctx context.Context, out chan<- Message) error {
+	sink, sub, err := s.cfg.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch logs: %w", err)
+	}
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-sink:
+				_ = s.deliver(ctx, out, log)
+				s.mu.Lock()
+				if log.BlockNumber > s.processed {
+					s.processed = log.BlockNumber
+				}
+				s.mu.Unlock()
+				_ = s.persist()
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// deliver decodes log, records it as pending and sends it to out as an unconfirmed Message.
+func (s *Stream) deliver(ctx context.Context, out chan<- Message, log types.Log) error {
+	ev, err := s.cfg.Decode(log)
+	if err != nil {
+		return fmt.Errorf("failed to decode log: %w", err)
+	}
+	s.mu.Lock()
+	s.pending = append(s.pending, pendingLog{Log: log})
+	s.mu.Unlock()
+
+	select {
+	case out <- Message{Event: ev, Log: log, Confirmed: false}:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// sweep checks every pending log against the canonical chain: logs whose block hash no longer
+// matches are reported Reverted, logs old enough and still canonical are reported Confirmed, and
+// the checkpoint is persisted if anything changed.
+func (s *Stream) sweep(ctx context.Context, out chan<- Message) {
+	head, err := s.cfg.Headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return
+	}
+	headNum := head.Number.Uint64()
+
+	s.mu.Lock()
+	pending := s.pending
+	s.mu.Unlock()
+
+	var remaining []pendingLog
+	changed := false
+	for _, p := range pending {
+		header, err := s.cfg.Headers.HeaderByNumber(ctx, new(big.Int).SetUint64(p.Log.BlockNumber))
+		if err != nil {
+			remaining = append(remaining, p)
+			continue
+		}
+		if header.Hash() != p.Log.BlockHash {
+			changed = true
+			select {
+			case out <- Message{Log: p.Log, Reverted: true}:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if headNum >= p.Log.BlockNumber+s.cfg.Confirmations {
+			changed = true
+			ev, err := s.cfg.Decode(p.Log)
+			if err != nil {
+				continue
+			}
+			select {
+			case out <- Message{Event: ev, Log: p.Log, Confirmed: true}:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+
+	s.mu.Lock()
+	s.pending = remaining
+	s.mu.Unlock()
+
+	if changed {
+		_ = s.persist()
+	}
+}
+
+// persist writes the current checkpoint to the configured Store.
+func (s *Stream) persist() error {
+	s.mu.Lock()
+	logs := make([]types.Log, len(s.pending))
+	for i, p := range s.pending {
+		logs[i] = p.Log
+	}
+	checkpoint := Checkpoint{ProcessedThrough: s.processed, Pending: logs}
+	s.mu.Unlock()
+	return s.cfg.Store.Save(checkpoint)
+}
+
+// PendingCount returns the number of logs still awaiting confirmation or reversion, useful for
+// metrics and tests.
+func (s *Stream) PendingCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
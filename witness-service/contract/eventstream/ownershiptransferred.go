@@ -0,0 +1,54 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package eventstream
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// OwnershipTransferredBackend is the subset of an RPC client NewOwnershipTransferredStream
+// needs: bind.ContractFilterer to poll/subscribe raw logs, and HeaderReader to gate on
+// confirmations.
+type OwnershipTransferredBackend interface {
+	bind.ContractFilterer
+	HeaderReader
+}
+
+// NewOwnershipTransferredStream builds a Stream filtered to topic on address, wiring backend's
+// FilterLogs/SubscribeFilterLogs into Filter/Watch and decode into Decode. ownershipguard,
+// metrics and exporter all watch AddressList's OwnershipTransferred event this same way; decode
+// is left to the caller (typically (*contract.AddressListFilterer).ParseOwnershipTransferred) so
+// this package still doesn't need to import any generated binding (see the package doc). Use
+// EventID(contract.AddressListABI, "OwnershipTransferred") to compute topic.
+func NewOwnershipTransferredStream(topic common.Hash, address common.Address, backend OwnershipTransferredBackend, decode DecodeFunc, confirmations uint64) (*Stream, error) {
+	query := ethereum.FilterQuery{Addresses: []common.Address{address}, Topics: [][]common.Hash{{topic}}}
+	return New(Config{
+		Filter: func(ctx context.Context, opts *bind.FilterOpts) ([]types.Log, error) {
+			q := query
+			q.FromBlock = new(big.Int).SetUint64(opts.Start)
+			if opts.End != nil {
+				q.ToBlock = new(big.Int).SetUint64(*opts.End)
+			}
+			return backend.FilterLogs(ctx, q)
+		},
+		Watch: func(ctx context.Context) (<-chan types.Log, event.Subscription, error) {
+			sink := make(chan types.Log)
+			sub, err := backend.SubscribeFilterLogs(ctx, query, sink)
+			return sink, sub, err
+		},
+		Decode:        decode,
+		Headers:       backend,
+		Confirmations: confirmations,
+	}), nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Checkpoint is the persisted state a Stream needs to resume: the highest block number it has
+// already queried logs up to, and every log seen but not yet confirmed or reverted.
+type Checkpoint struct {
+	ProcessedThrough uint64      `json:"processedThrough"`
+	Pending          []types.Log `json:"pending"`
+}
+
+// Store persists a Stream's Checkpoint so a restart resumes instead of reprocessing from genesis
+// or losing in-flight (unconfirmed) events. MemStore and FileStore below cover ad hoc/single-
+// process use; DBStore in store_db.go backs onto a SQL table for a long-running witness process.
+type Store interface {
+	Load() (Checkpoint, error)
+	Save(Checkpoint) error
+}
+
+// MemStore is an in-memory Store. It gives no durability across restarts; use it only where
+// losing in-flight unconfirmed events on a crash is acceptable, e.g. short-lived tooling.
+type MemStore struct {
+	mu         sync.Mutex
+	checkpoint Checkpoint
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{}
+}
+
+// Load implements Store.
+func (s *MemStore) Load() (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint, nil
+}
+
+// Save implements Store.
+func (s *MemStore) Save(checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoint = checkpoint
+	return nil
+}
+
+// FileStore persists a Checkpoint as a single JSON file on disk.
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore returns a Store that persists to path, creating it on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load implements Store, returning an empty Checkpoint if the file does not exist yet.
+func (s *FileStore) Load() (Checkpoint, error) {
+	var checkpoint Checkpoint
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoint, nil
+		}
+		return checkpoint, fmt.Errorf("failed to read eventstream checkpoint file %s: %w", s.Path, err)
+	}
+	if len(data) == 0 {
+		return checkpoint, nil
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("failed to parse eventstream checkpoint file %s: %w", s.Path, err)
+	}
+	return checkpoint, nil
+}
+
+// Save implements Store, overwriting whatever was persisted before.
+func (s *FileStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal eventstream checkpoint: %w", err)
+	}
+	if err := os.WriteFile(s.Path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write eventstream checkpoint file %s: %w", s.Path, err)
+	}
+	return nil
+}
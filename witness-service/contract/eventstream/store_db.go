@@ -0,0 +1,80 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package eventstream
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// DBStore persists a Checkpoint as a single row in a SQL table, the same storage tier
+// cmd/witness/main.go already uses for everything else (via db.NewStore(cfg.Database)). It takes
+// a plain *sql.DB rather than depending on the db package directly: db.NewStore's return type is
+// declared in the witness-service/db package, which is not part of this source tree, so its exact
+// shape cannot be depended on here. A caller that already has a db.NewStore(cfg.Database) handle
+// can pass its underlying *sql.DB straight through.
+type DBStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewDBStore returns a Store backed by table in db, creating table if it does not already exist.
+// table is expected to be a single-row-per-stream table; callers that run multiple Streams against
+// the same database should give each its own table name.
+func NewDBStore(db *sql.DB, table string) (*DBStore, error) {
+	s := &DBStore{db: db, table: table}
+	ddl := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (
+			id INT PRIMARY KEY,
+			processed_through BIGINT UNSIGNED NOT NULL,
+			pending JSON NOT NULL
+		)`,
+		table,
+	)
+	if _, err := s.db.Exec(ddl); err != nil {
+		return nil, fmt.Errorf("failed to create eventstream checkpoint table %s: %w", table, err)
+	}
+	return s, nil
+}
+
+// Load implements Store, returning an empty Checkpoint if no row has been saved yet.
+func (s *DBStore) Load() (Checkpoint, error) {
+	var checkpoint Checkpoint
+	var pending []byte
+	query := fmt.Sprintf("SELECT processed_through, pending FROM %s WHERE id = 1", s.table)
+	switch err := s.db.QueryRow(query).Scan(&checkpoint.ProcessedThrough, &pending); {
+	case err == sql.ErrNoRows:
+		return checkpoint, nil
+	case err != nil:
+		return checkpoint, fmt.Errorf("failed to load eventstream checkpoint from %s: %w", s.table, err)
+	}
+	if len(pending) == 0 {
+		return checkpoint, nil
+	}
+	if err := json.Unmarshal(pending, &checkpoint.Pending); err != nil {
+		return checkpoint, fmt.Errorf("failed to parse pending logs from %s: %w", s.table, err)
+	}
+	return checkpoint, nil
+}
+
+// Save implements Store, upserting the single persisted row.
+func (s *DBStore) Save(checkpoint Checkpoint) error {
+	pending, err := json.Marshal(checkpoint.Pending)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending logs: %w", err)
+	}
+	query := fmt.Sprintf(
+		`INSERT INTO %s (id, processed_through, pending) VALUES (1, ?, ?)
+			ON DUPLICATE KEY UPDATE processed_through = VALUES(processed_through), pending = VALUES(pending)`,
+		s.table,
+	)
+	if _, err := s.db.Exec(query, checkpoint.ProcessedThrough, pending); err != nil {
+		return fmt.Errorf("failed to save eventstream checkpoint to %s: %w", s.table, err)
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package rootchain provides a checkpoint-aware replacement for AddressListFilterer.WatchOwnershipTransferred
+// that only surfaces Polygon events once they have been checkpointed to the Ethereum root chain.
+package rootchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+)
+
+// rootChainABI is the subset of the Polygon RootChain ABI this connector needs.
+const rootChainABI = `[{"anonymous":false,"inputs":[{"indexed":true,"internalType":"uint256","name":"headerBlockId","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"start","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"end","type":"uint256"},{"indexed":false,"internalType":"bytes32","name":"root","type":"bytes32"},{"indexed":true,"internalType":"address","name":"proposer","type":"address"}],"name":"NewHeaderBlock","type":"event"}]`
+
+// defaultPollInterval is how often WatchOwnershipTransferred re-polls Polygon for new raw logs
+// and re-checks pending ones against the FinalityDelay fallback, between NewHeaderBlock events.
+const defaultPollInterval = 15 * time.Second
+
+// HeaderReader is the subset of a Polygon RPC client the connector needs to prune logs that a
+// reorg discarded and, when FinalityDelay is set, to tell how far chain head has advanced past a
+// pending log.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// pendingLog is an AddressList OwnershipTransferred log that has not yet been checkpointed.
+type pendingLog struct {
+	blockNumber uint64
+	blockHash   common.Hash
+	event       *contract.AddressListOwnershipTransferred
+}
+
+// headerBlock mirrors the range covered by a single Polygon checkpoint.
+type headerBlock struct {
+	start uint64
+	end   uint64
+}
+
+// Config holds the parameters needed to build a PolygonRootChainConnector.
+type Config struct {
+	// PolygonBackend is the RPC backend used to poll AddressList logs on Polygon.
+	PolygonBackend bind.ContractBackend
+	// RootChainBackend is the Ethereum RPC backend the Polygon RootChain contract is deployed on.
+	RootChainBackend bind.ContractFilterer
+	// RootChainAddress is the address of the Polygon RootChain contract on Ethereum.
+	RootChainAddress common.Address
+	// AddressListAddress is the address of the AddressList contract on Polygon.
+	AddressListAddress common.Address
+	// PolygonHeaders reads Polygon block headers, used to prune reorged pending logs and, with
+	// FinalityDelay, to release a log that has not yet been checkpointed. May be left nil if
+	// FinalityDelay is also left at zero, disabling both.
+	PolygonHeaders HeaderReader
+	// FinalityDelay is the number of confirmations to require before releasing a pending log that
+	// has not yet been checkpointed (e.g. while the root chain has fallen behind). Zero disables
+	// the fallback, so every log waits for an actual checkpoint.
+	FinalityDelay uint64
+}
+
+// PolygonRootChainConnector polls AddressList logs on Polygon and only releases them once the
+// Ethereum root chain has checkpointed the block they were emitted in. Its
+// FilterOwnershipTransferred/WatchOwnershipTransferred methods match the same-named methods on
+// contract.AddressListFilterer so it can be used as a drop-in replacement at call sites that
+// watch AddressList ownership transfers on a Polygon deployment.
+type PolygonRootChainConnector struct {
+	cfg Config
+
+	filterer  *contract.AddressListFilterer
+	rootChain *bind.BoundContract
+
+	mu      sync.Mutex
+	pending []*pendingLog
+	blocks  []headerBlock
+}
+
+// NewPolygonRootChainConnector creates a connector bound to the given Polygon AddressList and
+// Ethereum RootChain contracts.
+func NewPolygonRootChainConnector(cfg Config) (*PolygonRootChainConnector, error) {
+	filterer, err := contract.NewAddressListFilterer(cfg.AddressListAddress, cfg.PolygonBackend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind AddressList filterer: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(rootChainABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RootChain ABI: %w", err)
+	}
+	return &PolygonRootChainConnector{
+		cfg:       cfg,
+		filterer:  filterer,
+		rootChain: bind.NewBoundContract(cfg.RootChainAddress, parsed, nil, nil, cfg.RootChainBackend),
+	}, nil
+}
+
+// OwnershipTransferredIterator is returned from FilterOwnershipTransferred and iterates over the
+// checkpointed (or finality-delay-eligible) OwnershipTransferred events found in range, mirroring
+// contract.AddressListOwnershipTransferredIterator's Next/Error/Close shape.
+type OwnershipTransferredIterator struct {
+	Event *contract.AddressListOwnershipTransferred
+
+	events []*contract.AddressListOwnershipTransferred
+	idx    int
+}
+
+// Next advances the iterator, returning whether a further event is available.
+func (it *OwnershipTransferredIterator) Next() bool {
+	if it.idx >= len(it.events) {
+		return false
+	}
+	it.Event = it.events[it.idx]
+	it.idx++
+	return true
+}
+
+// Error always returns nil: every event in events was already retrieved successfully.
+func (it *OwnershipTransferredIterator) Error() error {
+	return nil
+}
+
+// Close is a no-op; OwnershipTransferredIterator holds no subscription to release.
+func (it *OwnershipTransferredIterator) Close() error {
+	return nil
+}
+
+// FilterOwnershipTransferred is a drop-in replacement for
+// contract.AddressListFilterer.FilterOwnershipTransferred: it returns only the logs in range that
+// the Ethereum root chain has already checkpointed, or that the FinalityDelay fallback considers
+// old enough to trust without one.
+func (c *PolygonRootChainConnector) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner, newOwner []common.Address) (*OwnershipTransferredIterator, error) {
+	ctx := contextOf(opts)
+
+	it, err := c.filterer.FilterOwnershipTransferred(opts, previousOwner, newOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter OwnershipTransferred logs: %w", err)
+	}
+	defer it.Close()
+
+	ranges, err := c.fetchCheckpointedRanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head := c.headBlock(ctx)
+
+	var ready []*contract.AddressListOwnershipTransferred
+	for it.Next() {
+		if coveredByRanges(ranges, it.Event.Raw.BlockNumber) || c.finalityDelayElapsed(it.Event.Raw.BlockNumber, head) {
+			ready = append(ready, it.Event)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate OwnershipTransferred logs: %w", err)
+	}
+	return &OwnershipTransferredIterator{events: ready}, nil
+}
+
+// WatchOwnershipTransferred is a drop-in replacement for
+// contract.AddressListFilterer.WatchOwnershipTransferred: it subscribes to NewHeaderBlock on the
+// Ethereum root chain and forwards a Polygon OwnershipTransferred log to sink only once its block
+// falls inside a checkpointed range, polling Polygon for new logs (and, under FinalityDelay, for
+// pending ones old enough to release early) every defaultPollInterval. Checkpointed ranges are
+// learned from NewHeaderBlock events received after the call; ranges checkpointed earlier are not
+// consulted.
+func (c *PolygonRootChainConnector) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *contract.AddressListOwnershipTransferred, previousOwner, newOwner []common.Address) (event.Subscription, error) {
+	ctx := contextOfWatch(opts)
+
+	logs, headerSub, err := c.rootChain.WatchLogs(&bind.WatchOpts{Context: ctx}, "NewHeaderBlock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch NewHeaderBlock: %w", err)
+	}
+
+	var fromBlock uint64
+	if opts != nil && opts.Start != nil {
+		fromBlock = *opts.Start
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer headerSub.Unsubscribe()
+
+		ticker := time.NewTicker(defaultPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case log := <-logs:
+				var checkpoint struct {
+					HeaderBlockId *big.Int
+					Start         *big.Int
+					End           *big.Int
+					Root          [32]byte
+					Proposer      common.Address
+				}
+				if err := c.rootChain.UnpackLog(&checkpoint, "NewHeaderBlock", log); err != nil {
+					return fmt.Errorf("failed to unpack NewHeaderBlock: %w", err)
+				}
+				c.recordCheckpoint(checkpoint.Start.Uint64(), checkpoint.End.Uint64())
+				c.flush(checkpoint.Start.Uint64(), checkpoint.End.Uint64(), sink, quit)
+			case <-ticker.C:
+				next, err := c.pollAndEnqueue(ctx, fromBlock, previousOwner, newOwner)
+				if err == nil {
+					fromBlock = next
+				}
+				c.mu.Lock()
+				recorded := append([]headerBlock(nil), c.blocks...)
+				c.mu.Unlock()
+				for _, b := range recorded {
+					c.flush(b.start, b.end, sink, quit)
+				}
+				c.flushFinalityDelayEligible(ctx, sink, quit)
+			case err := <-headerSub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// pollAndEnqueue fetches new OwnershipTransferred logs on Polygon from fromBlock onward and
+// enqueues them pending checkpoint confirmation, pruning previously queued logs a reorg
+// discarded. It returns the block number polling should resume from next time.
+func (c *PolygonRootChainConnector) pollAndEnqueue(ctx context.Context, fromBlock uint64, previousOwner, newOwner []common.Address) (uint64, error) {
+	it, err := c.filterer.FilterOwnershipTransferred(&bind.FilterOpts{Start: fromBlock, Context: ctx}, previousOwner, newOwner)
+	if err != nil {
+		return fromBlock, fmt.Errorf("failed to filter OwnershipTransferred logs: %w", err)
+	}
+	defer it.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneReorgedLogs(ctx)
+
+	toBlock := fromBlock
+	for it.Next() {
+		ev := it.Event
+		c.pending = append(c.pending, &pendingLog{
+			blockNumber: ev.Raw.BlockNumber,
+			blockHash:   ev.Raw.BlockHash,
+			event:       ev,
+		})
+		if ev.Raw.BlockNumber >= toBlock {
+			toBlock = ev.Raw.BlockNumber + 1
+		}
+	}
+	if err := it.Error(); err != nil {
+		return fromBlock, fmt.Errorf("failed to iterate OwnershipTransferred logs: %w", err)
+	}
+	return toBlock, nil
+}
+
+// pruneReorgedLogs drops queued logs whose block hash no longer matches what PolygonHeaders
+// reports for that height, i.e. a reorg discarded the block the log was emitted in. A no-op when
+// PolygonHeaders is nil. Callers must hold c.mu.
+func (c *PolygonRootChainConnector) pruneReorgedLogs(ctx context.Context) {
+	if c.cfg.PolygonHeaders == nil {
+		return
+	}
+	kept := c.pending[:0]
+	for _, p := range c.pending {
+		header, err := c.cfg.PolygonHeaders.HeaderByNumber(ctx, new(big.Int).SetUint64(p.blockNumber))
+		if err != nil || header.Hash() != p.blockHash {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	c.pending = kept
+}
+
+// recordCheckpoint remembers a checkpointed Polygon block range so WatchOwnershipTransferred's
+// poll can release logs inside it that were polled after its NewHeaderBlock arrived.
+// FilterOwnershipTransferred does not read it; it fetches the ranges from the root chain.
+func (c *PolygonRootChainConnector) recordCheckpoint(start, end uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = append(c.blocks, headerBlock{start: start, end: end})
+}
+
+// flush releases every pending log whose block number is covered by [start, end] to sink.
+func (c *PolygonRootChainConnector) flush(start, end uint64, sink chan<- *contract.AddressListOwnershipTransferred, quit <-chan struct{}) {
+	c.mu.Lock()
+	var remaining []*pendingLog
+	var ready []*pendingLog
+	for _, p := range c.pending {
+		if p.blockNumber >= start && p.blockNumber <= end {
+			ready = append(ready, p)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	c.pending = remaining
+	c.mu.Unlock()
+
+	for _, p := range ready {
+		select {
+		case sink <- p.event:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// flushFinalityDelayEligible releases every pending log old enough to trust under the
+// FinalityDelay fallback, without waiting for an actual checkpoint. A no-op when FinalityDelay or
+// PolygonHeaders is unset.
+func (c *PolygonRootChainConnector) flushFinalityDelayEligible(ctx context.Context, sink chan<- *contract.AddressListOwnershipTransferred, quit <-chan struct{}) {
+	head := c.headBlock(ctx)
+	if head == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	var remaining []*pendingLog
+	var ready []*pendingLog
+	for _, p := range c.pending {
+		if c.finalityDelayElapsed(p.blockNumber, head) {
+			ready = append(ready, p)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	c.pending = remaining
+	c.mu.Unlock()
+
+	for _, p := range ready {
+		select {
+		case sink <- p.event:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// fetchCheckpointedRanges returns every NewHeaderBlock range the root chain has recorded so far.
+func (c *PolygonRootChainConnector) fetchCheckpointedRanges(ctx context.Context) ([]headerBlock, error) {
+	logs, sub, err := c.rootChain.FilterLogs(&bind.FilterOpts{Context: ctx}, "NewHeaderBlock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter NewHeaderBlock logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	var ranges []headerBlock
+	// logs is never closed; sub.Err() closing marks the end, after which whatever is still
+	// buffered in logs is drained.
+	for done := false; !done || len(logs) > 0; {
+		var log types.Log
+		select {
+		case log = <-logs:
+		case err := <-sub.Err():
+			if err != nil {
+				return nil, fmt.Errorf("failed to filter NewHeaderBlock logs: %w", err)
+			}
+			done = true
+			continue
+		}
+		var checkpoint struct {
+			HeaderBlockId *big.Int
+			Start         *big.Int
+			End           *big.Int
+			Root          [32]byte
+			Proposer      common.Address
+		}
+		if err := c.rootChain.UnpackLog(&checkpoint, "NewHeaderBlock", log); err != nil {
+			return nil, fmt.Errorf("failed to unpack NewHeaderBlock: %w", err)
+		}
+		ranges = append(ranges, headerBlock{start: checkpoint.Start.Uint64(), end: checkpoint.End.Uint64()})
+	}
+	return ranges, nil
+}
+
+// coveredByRanges reports whether blockNumber falls inside any of ranges.
+func coveredByRanges(ranges []headerBlock, blockNumber uint64) bool {
+	for _, r := range ranges {
+		if blockNumber >= r.start && blockNumber <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// finalityDelayElapsed reports whether a log at blockNumber is old enough to release under the
+// N-confirmations fallback, given the current Polygon head. Always false when FinalityDelay or
+// PolygonHeaders is unset, or head is unknown (zero).
+func (c *PolygonRootChainConnector) finalityDelayElapsed(blockNumber, head uint64) bool {
+	if c.cfg.FinalityDelay == 0 || head == 0 {
+		return false
+	}
+	return head >= blockNumber+c.cfg.FinalityDelay
+}
+
+// headBlock returns the current Polygon chain head, or zero if PolygonHeaders is unset or the
+// read fails.
+func (c *PolygonRootChainConnector) headBlock(ctx context.Context) uint64 {
+	if c.cfg.PolygonHeaders == nil {
+		return 0
+	}
+	header, err := c.cfg.PolygonHeaders.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0
+	}
+	return header.Number.Uint64()
+}
+
+func contextOf(opts *bind.FilterOpts) context.Context {
+	if opts != nil && opts.Context != nil {
+		return opts.Context
+	}
+	return context.Background()
+}
+
+func contextOfWatch(opts *bind.WatchOpts) context.Context {
+	if opts != nil && opts.Context != nil {
+		return opts.Context
+	}
+	return context.Background()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package rootchain
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestUnpackNewHeaderBlock verifies that a real NewHeaderBlock log - start, end and root as
+// non-indexed args, headerBlockId and proposer as indexed ones - actually decodes, i.e. that the
+// unpack struct declares a field for every non-indexed arg instead of erroring "field root can't
+// be found" on every single checkpoint log, and a field for every indexed arg instead of
+// panicking inside abi.ParseTopics.
+func TestUnpackNewHeaderBlock(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(rootChainABI))
+	if err != nil {
+		t.Fatalf("failed to parse rootChainABI: %v", err)
+	}
+	ev, ok := parsed.Events["NewHeaderBlock"]
+	if !ok {
+		t.Fatalf("rootChainABI has no NewHeaderBlock event")
+	}
+	proposer := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	var root [32]byte
+	root[0] = 0xab
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(10), big.NewInt(20), root)
+	if err != nil {
+		t.Fatalf("failed to pack NewHeaderBlock data: %v", err)
+	}
+	log := types.Log{
+		Topics: []common.Hash{ev.ID, common.BigToHash(big.NewInt(7)), common.BytesToHash(proposer.Bytes())},
+		Data:   data,
+	}
+
+	rootChain := bind.NewBoundContract(common.Address{}, parsed, nil, nil, nil)
+	var checkpoint struct {
+		HeaderBlockId *big.Int
+		Start         *big.Int
+		End           *big.Int
+		Root          [32]byte
+		Proposer      common.Address
+	}
+	if err := rootChain.UnpackLog(&checkpoint, "NewHeaderBlock", log); err != nil {
+		t.Fatalf("UnpackLog returned error: %v", err)
+	}
+	if checkpoint.Start.Uint64() != 10 || checkpoint.End.Uint64() != 20 {
+		t.Fatalf("got start/end %d/%d, want 10/20", checkpoint.Start.Uint64(), checkpoint.End.Uint64())
+	}
+	if checkpoint.Root != root {
+		t.Fatalf("got root %x, want %x", checkpoint.Root, root)
+	}
+	if checkpoint.Proposer != proposer {
+		t.Fatalf("got proposer %s, want %s", checkpoint.Proposer, proposer)
+	}
+}
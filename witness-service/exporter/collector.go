@@ -0,0 +1,364 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package exporter exposes Prometheus metrics for one or more deployed AddressList contracts by
+// watching their OwnershipTransferred/ItemAdded/ItemDeactivated events, in the style of a
+// Chainlink-oracle-exporter but wired to ioTube's own generated bindings. Unlike the metrics
+// package (which polls the caller), exporter is event-driven and additionally tracks head-block
+// lag and subscription-error counts so operators can alert when a watcher falls behind or its
+// RPC connection drops.
+//
+// OwnershipTransferred is observed through eventstream rather than a raw WatchOwnershipTransferred,
+// so a reorg cannot make the ownership counter/gauge report a phantom change.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+	"github.com/iotexproject/ioTube/witness-service/contract/eventstream"
+)
+
+// Backend is the subset of an RPC client the exporter needs: the usual contract-bind backend,
+// plus HeaderByNumber to compute head-block lag and gate the OwnershipTransferred eventstream on
+// confirmations.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ListConfig identifies one deployed AddressList instance to export metrics for.
+type ListConfig struct {
+	Name    string
+	Address common.Address
+	Backend Backend
+}
+
+// Collector watches one or more AddressList contracts' events and exposes them as Prometheus
+// metrics.
+type Collector struct {
+	lists         []trackedList
+	confirmations uint64
+
+	owner              *prometheus.GaugeVec
+	ownershipTransfers *prometheus.CounterVec
+	allowlistSize      *prometheus.GaugeVec
+	addressChanges     *prometheus.CounterVec
+	headBlockLag       *prometheus.GaugeVec
+	subscriptionErrors *prometheus.CounterVec
+
+	mu        sync.Mutex
+	allowlist map[string]map[common.Address]struct{}
+	lastBlock map[string]uint64
+	lastOwner map[string]common.Address
+
+	cancel context.CancelFunc
+}
+
+type trackedList struct {
+	name     string
+	address  common.Address
+	caller   *contract.AddressListCaller
+	filterer *contract.AddressListFilterer
+	backend  Backend
+}
+
+// NewCollector builds a Collector for the given deployed AddressList instances and registers its
+// metrics with reg. confirmations is how many blocks an OwnershipTransferred event must age
+// before it is reflected in the owner gauge and ownershipTransfers counter.
+func NewCollector(reg prometheus.Registerer, lists []ListConfig, confirmations uint64) (*Collector, error) {
+	c := &Collector{
+		confirmations: confirmations,
+		owner: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_exporter_addresslist_owner",
+			Help: "Always 1; the current owner is carried as the addr label.",
+		}, []string{"list", "addr"}),
+		ownershipTransfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iotube_exporter_ownership_transfers_total",
+			Help: "Number of OwnershipTransferred events observed on an AddressList contract.",
+		}, []string{"list"}),
+		allowlistSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_exporter_addresslist_size",
+			Help: "Current number of active addresses tracked by an AddressList contract.",
+		}, []string{"list"}),
+		addressChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iotube_exporter_address_changes_total",
+			Help: "Number of per-address add/remove events observed on an AddressList contract.",
+		}, []string{"list", "addr", "change"}),
+		headBlockLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_exporter_head_block_lag",
+			Help: "Blocks between the chain head and the last AddressList event block observed, so operators can alert when a watcher falls behind.",
+		}, []string{"list"}),
+		subscriptionErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iotube_exporter_subscription_errors_total",
+			Help: "Number of times an AddressList event subscription errored out, e.g. because the RPC connection dropped.",
+		}, []string{"list", "event"}),
+		allowlist: make(map[string]map[common.Address]struct{}),
+		lastBlock: make(map[string]uint64),
+		lastOwner: make(map[string]common.Address),
+	}
+	for _, l := range lists {
+		caller, err := contract.NewAddressListCaller(l.Address, l.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind AddressList caller for %s: %w", l.Name, err)
+		}
+		filterer, err := contract.NewAddressListFilterer(l.Address, l.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind AddressList filterer for %s: %w", l.Name, err)
+		}
+		c.lists = append(c.lists, trackedList{name: l.Name, address: l.Address, caller: caller, filterer: filterer, backend: l.Backend})
+	}
+	for _, collector := range []prometheus.Collector{c.owner, c.ownershipTransfers, c.allowlistSize, c.addressChanges, c.headBlockLag, c.subscriptionErrors} {
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Start establishes the event subscriptions for every tracked list and the head-block lag poll
+// loop; it returns once subscriptions are established and runs until ctx is cancelled or Stop is
+// called.
+func (c *Collector) Start(ctx context.Context, headPollInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	for _, l := range c.lists {
+		if err := c.loadSnapshot(ctx, l); err != nil {
+			cancel()
+			return fmt.Errorf("failed to load initial snapshot for %s: %w", l.name, err)
+		}
+		if err := c.watchOwnershipTransferred(ctx, l, headPollInterval); err != nil {
+			cancel()
+			return err
+		}
+		if err := c.watchItemAdded(ctx, l); err != nil {
+			cancel()
+			return err
+		}
+		if err := c.watchItemDeactivated(ctx, l); err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(headPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.pollHeadBlockLag(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop tears down every subscription and poll loop started by Start.
+func (c *Collector) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// loadSnapshot seeds the owner gauge, allowlist size and per-address membership for l from the
+// contract's current state (Owner, NumOfActive and paged GetActiveItems reads), so the counters
+// exported before the first event arrives are not simply zero.
+func (c *Collector) loadSnapshot(ctx context.Context, l trackedList) error {
+	opts := &bind.CallOpts{Context: ctx}
+	if owner, err := l.caller.Owner(opts); err == nil {
+		c.setOwner(l.name, owner)
+	}
+	numActive, err := l.caller.NumOfActive(opts)
+	if err != nil {
+		return fmt.Errorf("failed to read numOfActive: %w", err)
+	}
+	c.allowlistSize.WithLabelValues(l.name).Set(float64(numActive.Int64()))
+
+	members := make(map[common.Address]struct{})
+	for offset := big.NewInt(0); ; offset = new(big.Int).Add(offset, big.NewInt(100)) {
+		page, err := l.caller.GetActiveItems(opts, offset, 100)
+		if err != nil {
+			return fmt.Errorf("failed to read active items: %w", err)
+		}
+		for _, item := range page.Items {
+			members[item] = struct{}{}
+		}
+		if len(page.Items) < 100 {
+			break
+		}
+	}
+	c.mu.Lock()
+	c.allowlist[l.name] = members
+	c.mu.Unlock()
+	return nil
+}
+
+// watchOwnershipTransferred runs an eventstream.Stream over OwnershipTransferred for l and updates
+// the owner gauge and transfer counter for every confirmed event received, so a reorg cannot make
+// either report a phantom change.
+func (c *Collector) watchOwnershipTransferred(ctx context.Context, l trackedList, pollInterval time.Duration) error {
+	stream, err := ownershipTransferredStream(l.address, l.backend, l.filterer, c.confirmations)
+	if err != nil {
+		return fmt.Errorf("failed to build OwnershipTransferred eventstream for %s: %w", l.name, err)
+	}
+	messages, err := stream.Run(ctx, pollInterval)
+	if err != nil {
+		return fmt.Errorf("failed to start OwnershipTransferred eventstream for %s: %w", l.name, err)
+	}
+	go func() {
+		for msg := range messages {
+			if !msg.Confirmed {
+				continue
+			}
+			ev := msg.Event.(*contract.AddressListOwnershipTransferred)
+			c.ownershipTransfers.WithLabelValues(l.name).Inc()
+			c.setOwner(l.name, ev.NewOwner)
+			c.recordBlock(l.name, ev.Raw.BlockNumber)
+		}
+	}()
+	return nil
+}
+
+// watchItemAdded subscribes to ItemAdded for l and updates the allowlist size gauge and
+// per-address add counter for every event received.
+func (c *Collector) watchItemAdded(ctx context.Context, l trackedList) error {
+	sink := make(chan *contract.AddressListItemAdded)
+	sub, err := l.filterer.WatchItemAdded(&bind.WatchOpts{Context: ctx}, sink, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch ItemAdded for %s: %w", l.name, err)
+	}
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-sink:
+				c.addressChanges.WithLabelValues(l.name, ev.Item.Hex(), "added").Inc()
+				c.applyMembership(l.name, ev.Item, true)
+				c.recordBlock(l.name, ev.Raw.BlockNumber)
+			case <-sub.Err():
+				c.subscriptionErrors.WithLabelValues(l.name, "ItemAdded").Inc()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// watchItemDeactivated subscribes to ItemDeactivated for l and updates the allowlist size gauge
+// and per-address remove counter for every event received.
+func (c *Collector) watchItemDeactivated(ctx context.Context, l trackedList) error {
+	sink := make(chan *contract.AddressListItemDeactivated)
+	sub, err := l.filterer.WatchItemDeactivated(&bind.WatchOpts{Context: ctx}, sink, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch ItemDeactivated for %s: %w", l.name, err)
+	}
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-sink:
+				c.addressChanges.WithLabelValues(l.name, ev.Item.Hex(), "removed").Inc()
+				c.applyMembership(l.name, ev.Item, false)
+				c.recordBlock(l.name, ev.Raw.BlockNumber)
+			case <-sub.Err():
+				c.subscriptionErrors.WithLabelValues(l.name, "ItemDeactivated").Inc()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// setOwner sets the owner gauge label for list to addr, clearing any previously reported owner
+// so the series does not accumulate stale label values across ownership transfers.
+func (c *Collector) setOwner(list string, addr common.Address) {
+	c.mu.Lock()
+	prev, had := c.lastOwner[list]
+	c.lastOwner[list] = addr
+	c.mu.Unlock()
+
+	if had && prev != addr {
+		c.owner.DeleteLabelValues(list, prev.Hex())
+	}
+	c.owner.WithLabelValues(list, addr.Hex()).Set(1)
+}
+
+// applyMembership updates the tracked allowlist for list and sets the allowlist size gauge to
+// match.
+func (c *Collector) applyMembership(list string, addr common.Address, active bool) {
+	c.mu.Lock()
+	members := c.allowlist[list]
+	if members == nil {
+		members = make(map[common.Address]struct{})
+		c.allowlist[list] = members
+	}
+	if active {
+		members[addr] = struct{}{}
+	} else {
+		delete(members, addr)
+	}
+	size := len(members)
+	c.mu.Unlock()
+
+	c.allowlistSize.WithLabelValues(list).Set(float64(size))
+}
+
+// recordBlock remembers the highest event block number seen for list, used by
+// pollHeadBlockLag to compute how far the watcher is behind the chain head.
+func (c *Collector) recordBlock(list string, blockNumber uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if blockNumber > c.lastBlock[list] {
+		c.lastBlock[list] = blockNumber
+	}
+}
+
+// pollHeadBlockLag refreshes the head-block-lag gauge for every tracked list.
+func (c *Collector) pollHeadBlockLag(ctx context.Context) {
+	for _, l := range c.lists {
+		head, err := l.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			continue
+		}
+		c.mu.Lock()
+		lastBlock := c.lastBlock[l.name]
+		c.mu.Unlock()
+		if lastBlock == 0 || head.Number.Uint64() < lastBlock {
+			continue
+		}
+		c.headBlockLag.WithLabelValues(l.name).Set(float64(head.Number.Uint64() - lastBlock))
+	}
+}
+
+// ownershipTransferredStream builds an eventstream.Stream over address's OwnershipTransferred
+// event, adapting watch (used only to decode logs back into *contract.AddressListOwnershipTransferred)
+// and backend (used to actually filter/subscribe/read headers).
+func ownershipTransferredStream(address common.Address, backend Backend, watch *contract.AddressListFilterer, confirmations uint64) (*eventstream.Stream, error) {
+	topic, err := eventstream.EventID(contract.AddressListABI, "OwnershipTransferred")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute OwnershipTransferred topic: %w", err)
+	}
+	return eventstream.NewOwnershipTransferredStream(topic, address, backend, func(log types.Log) (interface{}, error) {
+		return watch.ParseOwnershipTransferred(log)
+	}, confirmations)
+}
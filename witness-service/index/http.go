@@ -0,0 +1,66 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package index
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// HTTPHandler serves Service's two RPCs as JSON over HTTP, for embedding processes that have no
+// generated gRPC stubs for addresslistindex.proto to register against (see cmd/witness/main.go).
+// It answers GET /by-owner?owner=0x...&offset=&limit= and GET /all-owners?offset=&limit=.
+func (s *Service) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/by-owner", s.handleByOwner)
+	mux.HandleFunc("/all-owners", s.handleAllOwners)
+	return mux
+}
+
+func (s *Service) handleByOwner(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if !common.IsHexAddress(q.Get("owner")) {
+		http.Error(w, "missing or invalid owner", http.StatusBadRequest)
+		return
+	}
+	resp, err := s.ListByOwner(r.Context(), &ListByOwnerRequest{
+		Owner:  common.HexToAddress(q.Get("owner")),
+		Offset: atoiOrZero(q.Get("offset")),
+		Limit:  atoiOrZero(q.Get("limit")),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Service) handleAllOwners(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	resp, err := s.ListAllOwners(r.Context(), &ListAllOwnersRequest{
+		Offset: atoiOrZero(q.Get("offset")),
+		Limit:  atoiOrZero(q.Get("limit")),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package index maintains a reverse index over an AddressList contract's membership: for every
+// currently-active address, which owner was in control of the contract when that address was
+// added. This lets dashboards and the witness UI run ListByOwner/ListAllOwners queries locally
+// instead of re-scanning chain logs on every request.
+package index
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+)
+
+// HeaderReader is the subset of an RPC client the index needs to find the chain head and detect
+// reorgs.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Config holds the parameters an AddressListIndex is built with.
+type Config struct {
+	// Address is the deployed AddressList contract to index.
+	Address common.Address
+	// Backend is the RPC backend used to filter and watch logs.
+	Backend bind.ContractBackend
+	// Headers is used to find the chain head and to detect reorgs; it is typically the same
+	// client as Backend.
+	Headers HeaderReader
+	// FromBlock is the checkpoint to start (re)indexing from. Zero means genesis.
+	FromBlock uint64
+	// ReorgDepth is how many blocks back the index keeps a recorded header for; a mismatch at
+	// that depth between the recorded and current hash triggers a full reindex from FromBlock.
+	ReorgDepth uint64
+	// Store is the local key/value store the index materialises into. A nil Store defaults to
+	// a fresh MemStore.
+	Store Store
+}
+
+// event is a single OwnershipTransferred/ItemAdded/ItemDeactivated log, normalised enough to be
+// sorted into chain order regardless of which watcher produced it.
+type event struct {
+	blockNumber uint64
+	txIndex     uint
+	logIndex    uint
+	kind        eventKind
+	address     common.Address // the item added/removed; unused for ownership transfers
+	newOwner    common.Address // the new owner; unused for item add/remove
+}
+
+type eventKind int
+
+const (
+	eventOwnershipTransferred eventKind = iota
+	eventItemAdded
+	eventItemDeactivated
+)
+
+// AddressListIndex continuously ingests OwnershipTransferred, ItemAdded and ItemDeactivated logs
+// for a single AddressList contract and exposes owner-attribution queries over the result.
+type AddressListIndex struct {
+	cfg      Config
+	filterer *contract.AddressListFilterer
+	store    Store
+
+	mu           sync.RWMutex
+	watermark    uint64
+	currentOwner common.Address
+	recentBlocks map[uint64]common.Hash
+
+	cancel context.CancelFunc
+}
+
+// New builds an AddressListIndex bound to cfg.Address, but does not start ingesting; call Start
+// for that.
+func New(cfg Config) (*AddressListIndex, error) {
+	filterer, err := contract.NewAddressListFilterer(cfg.Address, cfg.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind AddressList filterer: %w", err)
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemStore()
+	}
+	return &AddressListIndex{
+		cfg:          cfg,
+		filterer:     filterer,
+		store:        store,
+		recentBlocks: make(map[uint64]common.Hash),
+	}, nil
+}
+
+// Start performs the initial reindex from cfg.FromBlock and begins tailing new logs plus the
+// reorg-detection poll loop. It returns once the initial reindex completes; call Stop (or cancel
+// ctx) to tear the index down.
+func (idx *AddressListIndex) Start(ctx context.Context, pollInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	idx.cancel = cancel
+
+	if err := idx.reindex(ctx); err != nil {
+		cancel()
+		return fmt.Errorf("failed initial reindex: %w", err)
+	}
+	if err := idx.tail(ctx); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				idx.checkForReorg(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop tears down every subscription and poll loop started by Start.
+func (idx *AddressListIndex) Stop() {
+	if idx.cancel != nil {
+		idx.cancel()
+	}
+}
+
+// reindex rebuilds the store from scratch by replaying every OwnershipTransferred, ItemAdded and
+// ItemDeactivated log between cfg.FromBlock and the current head, in chain order, so that every
+// address is attributed to whichever owner was in control at the time it was added.
+func (idx *AddressListIndex) reindex(ctx context.Context) error {
+	if err := idx.store.Reset(); err != nil {
+		return fmt.Errorf("failed to reset store: %w", err)
+	}
+
+	head, err := idx.cfg.Headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read chain head: %w", err)
+	}
+	toBlock := head.Number.Uint64()
+	opts := &bind.FilterOpts{Start: idx.cfg.FromBlock, End: &toBlock, Context: ctx}
+
+	events, err := idx.collectEvents(opts)
+	if err != nil {
+		return err
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].blockNumber != events[j].blockNumber {
+			return events[i].blockNumber < events[j].blockNumber
+		}
+		if events[i].txIndex != events[j].txIndex {
+			return events[i].txIndex < events[j].txIndex
+		}
+		return events[i].logIndex < events[j].logIndex
+	})
+
+	idx.mu.Lock()
+	idx.currentOwner = common.Address{}
+	for _, ev := range events {
+		idx.applyLocked(ev)
+	}
+	idx.watermark = toBlock
+	idx.recentBlocks = make(map[uint64]common.Hash)
+	idx.mu.Unlock()
+
+	return idx.recordRecentBlocks(ctx, toBlock)
+}
+
+// collectEvents pages through the three log types AddressListIndex cares about and returns them
+// as a single unsorted slice.
+func (idx *AddressListIndex) collectEvents(opts *bind.FilterOpts) ([]event, error) {
+	var events []event
+
+	ownerIt, err := idx.filterer.FilterOwnershipTransferred(opts, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter OwnershipTransferred: %w", err)
+	}
+	for ownerIt.Next() {
+		ev := ownerIt.Event
+		events = append(events, event{
+			blockNumber: ev.Raw.BlockNumber,
+			txIndex:     ev.Raw.TxIndex,
+			logIndex:    ev.Raw.Index,
+			kind:        eventOwnershipTransferred,
+			newOwner:    ev.NewOwner,
+		})
+	}
+	if err := ownerIt.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate OwnershipTransferred: %w", err)
+	}
+	ownerIt.Close()
+
+	addedIt, err := idx.filterer.FilterItemAdded(opts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter ItemAdded: %w", err)
+	}
+	for addedIt.Next() {
+		ev := addedIt.Event
+		events = append(events, event{
+			blockNumber: ev.Raw.BlockNumber,
+			txIndex:     ev.Raw.TxIndex,
+			logIndex:    ev.Raw.Index,
+			kind:        eventItemAdded,
+			address:     ev.Item,
+		})
+	}
+	if err := addedIt.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ItemAdded: %w", err)
+	}
+	addedIt.Close()
+
+	removedIt, err := idx.filterer.FilterItemDeactivated(opts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter ItemDeactivated: %w", err)
+	}
+	for removedIt.Next() {
+		ev := removedIt.Event
+		events = append(events, event{
+			blockNumber: ev.Raw.BlockNumber,
+			txIndex:     ev.Raw.TxIndex,
+			logIndex:    ev.Raw.Index,
+			kind:        eventItemDeactivated,
+			address:     ev.Item,
+		})
+	}
+	if err := removedIt.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ItemDeactivated: %w", err)
+	}
+	removedIt.Close()
+
+	return events, nil
+}
+
+// applyLocked applies a single chain-ordered event to the store and currentOwner. Callers must
+// hold idx.mu.
+func (idx *AddressListIndex) applyLocked(ev event) {
+	switch ev.kind {
+	case eventOwnershipTransferred:
+		idx.currentOwner = ev.newOwner
+	case eventItemAdded:
+		_ = idx.store.Put(ev.address, idx.currentOwner)
+	case eventItemDeactivated:
+		_ = idx.store.Delete(ev.address)
+	}
+}
+
+// tail subscribes to live OwnershipTransferred, ItemAdded and ItemDeactivated logs and applies
+// them to the store as they arrive. Live events across the three watchers are only
+// best-effort ordered relative to each other (each watcher preserves its own chain order, but
+// interleaving across watchers is not guaranteed). checkForReorg reindexes only when it detects
+// a reorg, so a misordered interleaving is otherwise left as applied.
+func (idx *AddressListIndex) tail(ctx context.Context) error {
+	ownerSink := make(chan *contract.AddressListOwnershipTransferred)
+	ownerSub, err := idx.filterer.WatchOwnershipTransferred(&bind.WatchOpts{Context: ctx}, ownerSink, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch OwnershipTransferred: %w", err)
+	}
+	addedSink := make(chan *contract.AddressListItemAdded)
+	addedSub, err := idx.filterer.WatchItemAdded(&bind.WatchOpts{Context: ctx}, addedSink, nil)
+	if err != nil {
+		ownerSub.Unsubscribe()
+		return fmt.Errorf("failed to watch ItemAdded: %w", err)
+	}
+	removedSink := make(chan *contract.AddressListItemDeactivated)
+	removedSub, err := idx.filterer.WatchItemDeactivated(&bind.WatchOpts{Context: ctx}, removedSink, nil)
+	if err != nil {
+		ownerSub.Unsubscribe()
+		addedSub.Unsubscribe()
+		return fmt.Errorf("failed to watch ItemDeactivated: %w", err)
+	}
+
+	go func() {
+		defer ownerSub.Unsubscribe()
+		defer addedSub.Unsubscribe()
+		defer removedSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-ownerSink:
+				idx.mu.Lock()
+				idx.applyLocked(event{kind: eventOwnershipTransferred, newOwner: ev.NewOwner})
+				idx.advanceWatermarkLocked(ev.Raw.BlockNumber)
+				idx.mu.Unlock()
+			case ev := <-addedSink:
+				idx.mu.Lock()
+				idx.applyLocked(event{kind: eventItemAdded, address: ev.Item})
+				idx.advanceWatermarkLocked(ev.Raw.BlockNumber)
+				idx.mu.Unlock()
+			case ev := <-removedSink:
+				idx.mu.Lock()
+				idx.applyLocked(event{kind: eventItemDeactivated, address: ev.Item})
+				idx.advanceWatermarkLocked(ev.Raw.BlockNumber)
+				idx.mu.Unlock()
+			case <-ownerSub.Err():
+				return
+			case <-addedSub.Err():
+				return
+			case <-removedSub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// advanceWatermarkLocked bumps the watermark to blockNumber if it is newer. Callers must hold
+// idx.mu.
+func (idx *AddressListIndex) advanceWatermarkLocked(blockNumber uint64) {
+	if blockNumber > idx.watermark {
+		idx.watermark = blockNumber
+	}
+}
+
+// recordRecentBlocks remembers the header hash of every block in [head-ReorgDepth, head], for
+// checkForReorg to compare against later.
+func (idx *AddressListIndex) recordRecentBlocks(ctx context.Context, head uint64) error {
+	start := uint64(0)
+	if head > idx.cfg.ReorgDepth {
+		start = head - idx.cfg.ReorgDepth
+	}
+	blocks := make(map[uint64]common.Hash)
+	for n := start; n <= head; n++ {
+		header, err := idx.cfg.Headers.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			continue
+		}
+		blocks[n] = header.Hash()
+	}
+	idx.mu.Lock()
+	idx.recentBlocks = blocks
+	idx.mu.Unlock()
+	return nil
+}
+
+// checkForReorg compares the recorded hash at head-ReorgDepth against the chain's current hash
+// for that height. A mismatch means a reorg deeper than ReorgDepth happened since the last
+// reindex, so the whole index is rebuilt from cfg.FromBlock.
+func (idx *AddressListIndex) checkForReorg(ctx context.Context) {
+	head, err := idx.cfg.Headers.HeaderByNumber(ctx, nil)
+	if err != nil || head.Number.Uint64() < idx.cfg.ReorgDepth {
+		return
+	}
+	checkpoint := head.Number.Uint64() - idx.cfg.ReorgDepth
+
+	idx.mu.RLock()
+	recorded, ok := idx.recentBlocks[checkpoint]
+	idx.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	current, err := idx.cfg.Headers.HeaderByNumber(ctx, new(big.Int).SetUint64(checkpoint))
+	if err != nil {
+		return
+	}
+	if current.Hash() == recorded {
+		_ = idx.recordRecentBlocks(ctx, head.Number.Uint64())
+		return
+	}
+	_ = idx.reindex(ctx)
+}
+
+// Watermark returns the block number up to which the index has ingested events, for cache
+// invalidation: a consumer that cached a response alongside its watermark knows to refetch once
+// Watermark advances.
+func (idx *AddressListIndex) Watermark() uint64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.watermark
+}
+
+// ListByOwner returns the page of addresses, starting at offset and at most limit entries, that
+// were introduced while owner controlled the contract, plus the total count and the watermark
+// the result is consistent with.
+func (idx *AddressListIndex) ListByOwner(ctx context.Context, owner common.Address, offset, limit int) ([]common.Address, int, uint64, error) {
+	addrs, total, err := idx.store.ListByOwner(owner, offset, limit)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to list by owner: %w", err)
+	}
+	return addrs, total, idx.Watermark(), nil
+}
+
+// ListAllOwners returns the page of distinct owners, starting at offset and at most limit
+// entries, that have introduced at least one currently-active address, plus the total count and
+// the watermark the result is consistent with.
+func (idx *AddressListIndex) ListAllOwners(ctx context.Context, offset, limit int) ([]common.Address, int, uint64, error) {
+	owners, total, err := idx.store.ListAllOwners(offset, limit)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to list all owners: %w", err)
+	}
+	return owners, total, idx.Watermark(), nil
+}
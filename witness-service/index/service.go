@@ -0,0 +1,85 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package index
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultPageSize is used when a request does not specify a limit.
+const defaultPageSize = 100
+
+// ListByOwnerRequest is the request shape for Service.ListByOwner, mirroring a Consul-style
+// list-by-owner query: page through a potentially large membership with Offset/Limit, and use
+// Watermark the same way a Consul blocking query uses its index, i.e. to tell whether the
+// result could have changed since a previous call.
+type ListByOwnerRequest struct {
+	Owner  common.Address
+	Offset int
+	Limit  int
+}
+
+// ListByOwnerResponse is the response shape for Service.ListByOwner.
+type ListByOwnerResponse struct {
+	Addresses []common.Address
+	Total     int
+	Watermark uint64
+}
+
+// ListAllOwnersRequest is the request shape for Service.ListAllOwners.
+type ListAllOwnersRequest struct {
+	Offset int
+	Limit  int
+}
+
+// ListAllOwnersResponse is the response shape for Service.ListAllOwners.
+type ListAllOwnersResponse struct {
+	Owners    []common.Address
+	Total     int
+	Watermark uint64
+}
+
+// Service is the RPC-facing wrapper around an AddressListIndex. Its methods mirror the two RPCs
+// of addresslistindex.proto, but its request and response types use common.Address and int where
+// the proto uses hex strings and int32, so a generated gRPC server has to convert between the
+// two before delegating to it.
+type Service struct {
+	index *AddressListIndex
+}
+
+// NewService wraps index for RPC serving.
+func NewService(index *AddressListIndex) *Service {
+	return &Service{index: index}
+}
+
+// ListByOwner backs the AddressListIndexService.ListByOwner RPC.
+func (s *Service) ListByOwner(ctx context.Context, req *ListByOwnerRequest) (*ListByOwnerResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	addrs, total, watermark, err := s.index.ListByOwner(ctx, req.Owner, req.Offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListByOwnerResponse{Addresses: addrs, Total: total, Watermark: watermark}, nil
+}
+
+// ListAllOwners backs the AddressListIndexService.ListAllOwners RPC.
+func (s *Service) ListAllOwners(ctx context.Context, req *ListAllOwnersRequest) (*ListAllOwnersResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	owners, total, watermark, err := s.index.ListAllOwners(ctx, req.Offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListAllOwnersResponse{Owners: owners, Total: total, Watermark: watermark}, nil
+}
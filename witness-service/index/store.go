@@ -0,0 +1,131 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package index
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Store is the local key/value store an AddressListIndex materialises its owner attribution
+// into: for every currently-active address, which owner introduced it.
+type Store interface {
+	// Put records that addr is currently active and was introduced by owner.
+	Put(addr, owner common.Address) error
+	// Delete removes addr, e.g. once it has been deactivated on-chain.
+	Delete(addr common.Address) error
+	// ListByOwner returns the page of addresses introduced by owner starting at offset, and the
+	// total number of addresses introduced by owner.
+	ListByOwner(owner common.Address, offset, limit int) ([]common.Address, int, error)
+	// ListAllOwners returns the page of distinct owners that have introduced at least one
+	// currently-active address, starting at offset, and the total number of distinct owners.
+	ListAllOwners(offset, limit int) ([]common.Address, int, error)
+	// Reset clears the store entirely, ahead of a reindex from scratch.
+	Reset() error
+}
+
+// MemStore is an in-memory Store. It is the default used by AddressListIndex; callers that need
+// the index to survive a restart without a full reindex can provide their own Store.
+type MemStore struct {
+	mu      sync.RWMutex
+	ownerOf map[common.Address]common.Address
+	byOwner map[common.Address]map[common.Address]struct{}
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		ownerOf: make(map[common.Address]common.Address),
+		byOwner: make(map[common.Address]map[common.Address]struct{}),
+	}
+}
+
+// Put implements Store.
+func (s *MemStore) Put(addr, owner common.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prevOwner, ok := s.ownerOf[addr]; ok {
+		delete(s.byOwner[prevOwner], addr)
+	}
+	s.ownerOf[addr] = owner
+	members, ok := s.byOwner[owner]
+	if !ok {
+		members = make(map[common.Address]struct{})
+		s.byOwner[owner] = members
+	}
+	members[addr] = struct{}{}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemStore) Delete(addr common.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner, ok := s.ownerOf[addr]
+	if !ok {
+		return nil
+	}
+	delete(s.ownerOf, addr)
+	delete(s.byOwner[owner], addr)
+	return nil
+}
+
+// ListByOwner implements Store.
+func (s *MemStore) ListByOwner(owner common.Address, offset, limit int) ([]common.Address, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	members := sortedAddresses(s.byOwner[owner])
+	return paginate(members, offset, limit), len(members), nil
+}
+
+// ListAllOwners implements Store.
+func (s *MemStore) ListAllOwners(offset, limit int) ([]common.Address, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	owners := make([]common.Address, 0, len(s.byOwner))
+	for owner, members := range s.byOwner {
+		if len(members) > 0 {
+			owners = append(owners, owner)
+		}
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Hex() < owners[j].Hex() })
+	return paginate(owners, offset, limit), len(owners), nil
+}
+
+// Reset implements Store.
+func (s *MemStore) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ownerOf = make(map[common.Address]common.Address)
+	s.byOwner = make(map[common.Address]map[common.Address]struct{})
+	return nil
+}
+
+// sortedAddresses returns the keys of set in a stable, deterministic order so pagination is
+// consistent across calls.
+func sortedAddresses(set map[common.Address]struct{}) []common.Address {
+	out := make([]common.Address, 0, len(set))
+	for addr := range set {
+		out = append(out, addr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hex() < out[j].Hex() })
+	return out
+}
+
+// paginate returns the slice of addrs starting at offset, at most limit entries.
+func paginate(addrs []common.Address, offset, limit int) []common.Address {
+	if offset >= len(addrs) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(addrs) {
+		end = len(addrs)
+	}
+	return addrs[offset:end]
+}
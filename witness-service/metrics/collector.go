@@ -0,0 +1,242 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package metrics exposes Prometheus metrics for the deployed AddressList (and, by the same
+// shape, witness/relayer) contracts by periodically polling the generated bindings and by
+// counting the ownership-transfer events they emit. Ownership transfers are observed through
+// eventstream rather than a raw WatchOwnershipTransferred, so a reorg cannot make the
+// ownershipTransfers counter or owner gauge report a phantom change.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+	"github.com/iotexproject/ioTube/witness-service/contract/eventstream"
+)
+
+// maxTrackedAddresses caps the number of distinct addr/owner label values a Collector will emit
+// per list, so an event flood (or a misconfigured list) cannot blow up Prometheus label cardinality.
+const maxTrackedAddresses = 1000
+
+// Backend is the subset of an RPC client the Collector needs: the usual contract-bind backend,
+// plus HeaderByNumber so the OwnershipTransferred eventstream can gate on confirmations.
+type Backend interface {
+	bind.ContractBackend
+	eventstream.HeaderReader
+}
+
+// ListConfig identifies one deployed AddressList instance to export metrics for.
+type ListConfig struct {
+	Name    string
+	Address common.Address
+	Backend Backend
+}
+
+// Collector polls one or more AddressList contracts and exposes their state, plus a running
+// count of ownership-transfer events, as Prometheus metrics.
+type Collector struct {
+	lists         []trackedList
+	confirmations uint64
+
+	count              *prometheus.GaugeVec
+	numActive          *prometheus.GaugeVec
+	owner              *prometheus.GaugeVec
+	ownershipTransfers *prometheus.CounterVec
+
+	mu     sync.Mutex
+	seen   map[string]map[common.Address]struct{}
+	cancel context.CancelFunc
+}
+
+type trackedList struct {
+	name    string
+	address common.Address
+	backend Backend
+	caller  *contract.AddressListCaller
+	watch   *contract.AddressListFilterer
+}
+
+// NewCollector builds a Collector for the given list of deployed AddressList instances and
+// registers its metrics with reg. confirmations is how many blocks an OwnershipTransferred event
+// must age before it is reflected in the owner gauge and ownershipTransfers counter.
+func NewCollector(reg prometheus.Registerer, lists []ListConfig, confirmations uint64) (*Collector, error) {
+	c := &Collector{
+		confirmations: confirmations,
+		count: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_addresslist_count",
+			Help: "Total number of items (active and inactive) tracked by an AddressList contract.",
+		}, []string{"list"}),
+		numActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_addresslist_num_active",
+			Help: "Number of active items tracked by an AddressList contract.",
+		}, []string{"list"}),
+		owner: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "iotube_addresslist_owner",
+			Help: "Always 1; the current owner is carried as the addr label.",
+		}, []string{"list", "addr"}),
+		ownershipTransfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iotube_ownership_transfers_total",
+			Help: "Number of OwnershipTransferred events observed on an AddressList contract.",
+		}, []string{"list"}),
+		seen: make(map[string]map[common.Address]struct{}),
+	}
+	for _, l := range lists {
+		caller, err := contract.NewAddressListCaller(l.Address, l.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind AddressList caller for %s: %w", l.Name, err)
+		}
+		filterer, err := contract.NewAddressListFilterer(l.Address, l.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind AddressList filterer for %s: %w", l.Name, err)
+		}
+		c.lists = append(c.lists, trackedList{name: l.Name, address: l.Address, backend: l.Backend, caller: caller, watch: filterer})
+	}
+	for _, collector := range []prometheus.Collector{c.count, c.numActive, c.owner, c.ownershipTransfers} {
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Start launches the periodic poll loop and the ownership-transfer watchers; it returns
+// immediately and runs until ctx is cancelled or Stop is called.
+func (c *Collector) Start(ctx context.Context, pollInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	for _, l := range c.lists {
+		l := l
+		stream, err := ownershipTransferredStream(l.address, l.backend, l.watch, c.confirmations)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to build OwnershipTransferred eventstream for %s: %w", l.name, err)
+		}
+		messages, err := stream.Run(ctx, pollInterval)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to start OwnershipTransferred eventstream for %s: %w", l.name, err)
+		}
+		go func() {
+			for msg := range messages {
+				if !msg.Confirmed {
+					continue
+				}
+				ev := msg.Event.(*contract.AddressListOwnershipTransferred)
+				c.ownershipTransfers.WithLabelValues(l.name).Inc()
+				c.setOwner(l.name, ev.NewOwner)
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		c.poll(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				c.poll(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop halts the poll loop and watchers started by Start.
+func (c *Collector) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// pageSize is the limit passed to GetActiveItems when paginating as a fallback for chains whose
+// numOfActive view is unreliable or absent.
+const pageSize = 100
+
+// poll refreshes the count/numActive/owner gauges for every tracked list via the caller session.
+func (c *Collector) poll(ctx context.Context) {
+	opts := &bind.CallOpts{Context: ctx}
+	for _, l := range c.lists {
+		if count, err := l.caller.Count(opts); err == nil {
+			c.count.WithLabelValues(l.name).Set(float64(count.Int64()))
+		}
+		if active, err := l.caller.NumOfActive(opts); err == nil {
+			c.numActive.WithLabelValues(l.name).Set(float64(active.Int64()))
+		} else if active, err := c.countActiveByPaging(opts, l.caller); err == nil {
+			c.numActive.WithLabelValues(l.name).Set(float64(active))
+		}
+		if owner, err := l.caller.Owner(opts); err == nil {
+			c.setOwner(l.name, owner)
+		}
+	}
+}
+
+// countActiveByPaging counts active items by walking GetActiveItems a page at a time, for
+// deployments whose numOfActive() view reverts or is otherwise unavailable.
+func (c *Collector) countActiveByPaging(opts *bind.CallOpts, caller *contract.AddressListCaller) (int, error) {
+	total := 0
+	offset := big.NewInt(0)
+	for {
+		page, err := caller.GetActiveItems(opts, offset, uint8(pageSize))
+		if err != nil {
+			return 0, err
+		}
+		total += len(page.Items)
+		if len(page.Items) < pageSize {
+			return total, nil
+		}
+		offset = new(big.Int).Add(offset, big.NewInt(pageSize))
+	}
+}
+
+// setOwner sets the owner gauge label for list to addr, clearing any previously reported owner
+// so the series does not accumulate stale label values across rotations.
+func (c *Collector) setOwner(list string, addr common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tracked, ok := c.seen[list]
+	if !ok {
+		tracked = make(map[common.Address]struct{})
+		c.seen[list] = tracked
+	}
+	if _, ok := tracked[addr]; !ok {
+		if len(tracked) >= maxTrackedAddresses {
+			return // cap label cardinality; keep reporting the owners already being tracked
+		}
+		for prev := range tracked {
+			c.owner.DeleteLabelValues(list, prev.Hex())
+		}
+		tracked = map[common.Address]struct{}{addr: {}}
+		c.seen[list] = tracked
+	}
+	c.owner.WithLabelValues(list, addr.Hex()).Set(1)
+}
+
+// ownershipTransferredStream builds an eventstream.Stream over address's OwnershipTransferred
+// event, adapting watch (used only to decode logs back into *contract.AddressListOwnershipTransferred)
+// and backend (used to actually filter/subscribe/read headers).
+func ownershipTransferredStream(address common.Address, backend Backend, watch *contract.AddressListFilterer, confirmations uint64) (*eventstream.Stream, error) {
+	topic, err := eventstream.EventID(contract.AddressListABI, "OwnershipTransferred")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute OwnershipTransferred topic: %w", err)
+	}
+	return eventstream.NewOwnershipTransferredStream(topic, address, backend, func(log types.Log) (interface{}, error) {
+		return watch.ParseOwnershipTransferred(log)
+	}, confirmations)
+}
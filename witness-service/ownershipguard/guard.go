@@ -0,0 +1,305 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package ownershipguard is a cross-cutting safety guard any witness or relayer can plug into.
+// It watches OwnershipTransferred on one or more AddressList (or other ownable bridge) contracts
+// and, the moment a transfer is observed, trips that contract: callers are expected to check
+// Allowed before signing or submitting a cross-chain transfer, and to refuse while tripped. A
+// trip only clears itself once the transfer has reached a configurable confirmation depth AND
+// the new owner is on an operator-supplied allowlist of known multisigs; otherwise it stays
+// tripped until a human calls Resume.
+//
+// OwnershipTransferred is observed through eventstream rather than a raw WatchOwnershipTransferred,
+// so a shallow reorg that un-mines the transfer trips Reverted instead of leaving a phantom trip
+// in place. The stream keeps its checkpoint in memory, so after a restart it backfills from block
+// 1 again; the trips themselves survive a restart only through Config.Store.
+package ownershipguard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+	"github.com/iotexproject/ioTube/witness-service/contract/eventstream"
+)
+
+// HeaderReader is the subset of an RPC client the guard needs to measure confirmation depth.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Backend is the subset of an RPC client the guard needs to watch a contract: the usual
+// contract-bind backend, plus HeaderReader so the underlying eventstream can gate on
+// confirmations and detect reverted logs.
+type Backend interface {
+	bind.ContractBackend
+	HeaderReader
+}
+
+// Trip describes why a contract is currently tripped.
+type Trip struct {
+	Contract      common.Address `json:"contract"`
+	PreviousOwner common.Address `json:"previousOwner"`
+	NewOwner      common.Address `json:"newOwner"`
+	BlockNumber   uint64         `json:"blockNumber"`
+	Confirmed     bool           `json:"confirmed"`
+	Allowlisted   bool           `json:"allowlisted"`
+}
+
+// Status is the ops-facing view of a single tracked contract, used by the guard's status RPC.
+type Status struct {
+	Name    string `json:"name"`
+	Tripped bool   `json:"tripped"`
+	Trip    *Trip  `json:"trip,omitempty"`
+}
+
+// Config holds the parameters a Guard is built with.
+type Config struct {
+	// ConfirmationDepth is how many blocks must pass after an OwnershipTransferred event before
+	// the guard will consider auto-clearing the trip it caused.
+	ConfirmationDepth uint64
+	// Allowlist is the set of known multisig addresses a new owner may be without requiring a
+	// human to call Resume.
+	Allowlist []common.Address
+	// Store persists trip state so a restart does not lose a tripped condition. A nil Store
+	// disables persistence (state is kept in memory only).
+	Store Store
+}
+
+// watchedContract is the state the Guard keeps for one monitored contract.
+type watchedContract struct {
+	name     string
+	address  common.Address
+	backend  Backend
+	filterer *contract.AddressListFilterer
+}
+
+// Guard watches one or more ownable contracts and gates cross-chain transfer signing/submission
+// while any of them is tripped.
+type Guard struct {
+	cfg       Config
+	allowlist map[common.Address]struct{}
+
+	mu       sync.RWMutex
+	watching map[common.Address]watchedContract
+	trips    map[common.Address]*Trip
+
+	cancel context.CancelFunc
+}
+
+// New builds a Guard from cfg, restoring any previously persisted trips from cfg.Store.
+func New(cfg Config) (*Guard, error) {
+	allowlist := make(map[common.Address]struct{}, len(cfg.Allowlist))
+	for _, addr := range cfg.Allowlist {
+		allowlist[addr] = struct{}{}
+	}
+	g := &Guard{
+		cfg:       cfg,
+		allowlist: allowlist,
+		watching:  make(map[common.Address]watchedContract),
+		trips:     make(map[common.Address]*Trip),
+	}
+	if cfg.Store != nil {
+		trips, err := cfg.Store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load persisted guard state: %w", err)
+		}
+		g.trips = trips
+	}
+	return g, nil
+}
+
+// Watch registers address (identified for humans by name) to be monitored for
+// OwnershipTransferred, binding its own AddressList filterer against backend. It must be called
+// before Start.
+func (g *Guard) Watch(name string, address common.Address, backend Backend) error {
+	filterer, err := contract.NewAddressListFilterer(address, backend)
+	if err != nil {
+		return fmt.Errorf("failed to bind AddressList filterer for %s: %w", name, err)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.watching[address] = watchedContract{name: name, address: address, backend: backend, filterer: filterer}
+	return nil
+}
+
+// Start subscribes to OwnershipTransferred on every watched contract through an eventstream.Stream
+// gated at cfg.ConfirmationDepth confirmations. It returns once the subscriptions are established;
+// call Stop (or cancel ctx) to tear the guard down.
+func (g *Guard) Start(ctx context.Context, pollInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	g.cancel = cancel
+
+	g.mu.RLock()
+	watched := make([]watchedContract, 0, len(g.watching))
+	for _, w := range g.watching {
+		watched = append(watched, w)
+	}
+	g.mu.RUnlock()
+
+	for _, w := range watched {
+		if err := g.watchContract(ctx, w, pollInterval); err != nil {
+			cancel()
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop tears down every subscription and poll loop started by Start.
+func (g *Guard) Stop() {
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// watchContract runs an eventstream.Stream over w's OwnershipTransferred event and trips (or
+// un-trips, on Reverted) the guard as messages arrive.
+func (g *Guard) watchContract(ctx context.Context, w watchedContract, pollInterval time.Duration) error {
+	stream, err := ownershipTransferredStream(w.address, w.backend, w.filterer, g.cfg.ConfirmationDepth)
+	if err != nil {
+		return fmt.Errorf("failed to build OwnershipTransferred eventstream for %s: %w", w.name, err)
+	}
+	messages, err := stream.Run(ctx, pollInterval)
+	if err != nil {
+		return fmt.Errorf("failed to start OwnershipTransferred eventstream for %s: %w", w.name, err)
+	}
+	go func() {
+		for msg := range messages {
+			if msg.Reverted {
+				g.untrip(w.address, msg.Log.BlockNumber)
+				continue
+			}
+			ev := msg.Event.(*contract.AddressListOwnershipTransferred)
+			g.trip(w.address, ev, msg.Confirmed)
+		}
+	}()
+	return nil
+}
+
+// trip records a Trip for contractAddr, or promotes the existing one to confirmed, and persists
+// it. A trip that is both confirmed and allowlisted auto-clears instead of being recorded.
+func (g *Guard) trip(contractAddr common.Address, ev *contract.AddressListOwnershipTransferred, confirmed bool) {
+	_, allowlisted := g.allowlist[ev.NewOwner]
+	if confirmed && allowlisted {
+		g.mu.Lock()
+		delete(g.trips, contractAddr)
+		g.mu.Unlock()
+		g.persist()
+		return
+	}
+	t := &Trip{
+		Contract:      contractAddr,
+		PreviousOwner: ev.PreviousOwner,
+		NewOwner:      ev.NewOwner,
+		BlockNumber:   ev.Raw.BlockNumber,
+		Confirmed:     confirmed,
+		Allowlisted:   allowlisted,
+	}
+	g.mu.Lock()
+	g.trips[contractAddr] = t
+	g.mu.Unlock()
+	g.persist()
+}
+
+// untrip clears the trip for contractAddr if it is the one caused by the event at blockNumber,
+// which the eventstream has now reported Reverted, i.e. it was never actually confirmed.
+func (g *Guard) untrip(contractAddr common.Address, blockNumber uint64) {
+	g.mu.Lock()
+	if t, ok := g.trips[contractAddr]; ok && t.BlockNumber == blockNumber {
+		delete(g.trips, contractAddr)
+	}
+	g.mu.Unlock()
+	g.persist()
+}
+
+// Allowed reports whether cross-chain transfers may be signed/submitted for contractAddr, i.e.
+// it is not currently tripped.
+func (g *Guard) Allowed(contractAddr common.Address) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, tripped := g.trips[contractAddr]
+	return !tripped
+}
+
+// Resume clears the trip for contractAddr regardless of confirmation depth or allowlist status,
+// for an operator to call once they have manually verified the new owner.
+func (g *Guard) Resume(ctx context.Context, contractAddr common.Address) error {
+	g.mu.Lock()
+	delete(g.trips, contractAddr)
+	g.mu.Unlock()
+	return g.persistCtx(ctx)
+}
+
+// Status returns the current trip state of every watched contract, for an ops-facing status RPC.
+func (g *Guard) Status() []Status {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	statuses := make([]Status, 0, len(g.watching))
+	for addr, w := range g.watching {
+		s := Status{Name: w.name}
+		if t, ok := g.trips[addr]; ok {
+			s.Tripped = true
+			s.Trip = t
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// StatusHandler is the status RPC referenced throughout this package's docs: an http.Handler
+// that serves Status() as JSON, for an operator dashboard or curl to poll and display why a
+// contract is currently tripped.
+func (g *Guard) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(g.Status()); err != nil {
+			http.Error(w, fmt.Sprintf("failed to encode status: %v", err), http.StatusInternalServerError)
+		}
+	})
+}
+
+// persist writes the current trip state to cfg.Store, if configured, logging nothing on failure
+// beyond returning it to callers that care (trip/untrip run from goroutines that do not, so they
+// best-effort it).
+func (g *Guard) persist() {
+	_ = g.persistCtx(context.Background())
+}
+
+func (g *Guard) persistCtx(ctx context.Context) error {
+	if g.cfg.Store == nil {
+		return nil
+	}
+	g.mu.RLock()
+	trips := make(map[common.Address]*Trip, len(g.trips))
+	for addr, t := range g.trips {
+		trips[addr] = t
+	}
+	g.mu.RUnlock()
+	return g.cfg.Store.Save(trips)
+}
+
+// ownershipTransferredStream builds an eventstream.Stream over address's OwnershipTransferred
+// event, adapting watch (used only to decode logs back into *contract.AddressListOwnershipTransferred)
+// and backend (used to actually filter/subscribe/read headers).
+func ownershipTransferredStream(address common.Address, backend Backend, watch *contract.AddressListFilterer, confirmations uint64) (*eventstream.Stream, error) {
+	topic, err := eventstream.EventID(contract.AddressListABI, "OwnershipTransferred")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute OwnershipTransferred topic: %w", err)
+	}
+	return eventstream.NewOwnershipTransferredStream(topic, address, backend, func(log types.Log) (interface{}, error) {
+		return watch.ParseOwnershipTransferred(log)
+	}, confirmations)
+}
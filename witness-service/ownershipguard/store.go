@@ -0,0 +1,64 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package ownershipguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Store persists a Guard's trip state so a restart does not lose a tripped condition.
+type Store interface {
+	// Load returns the previously persisted trips, or an empty map if none have been saved yet.
+	Load() (map[common.Address]*Trip, error)
+	// Save overwrites the persisted trips with trips.
+	Save(trips map[common.Address]*Trip) error
+}
+
+// FileStore persists trip state as a single JSON file on disk.
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore returns a Store that persists to path, creating it on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load reads the trip state from disk, returning an empty map if the file does not exist yet.
+func (s *FileStore) Load() (map[common.Address]*Trip, error) {
+	trips := make(map[common.Address]*Trip)
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return trips, nil
+		}
+		return nil, fmt.Errorf("failed to read guard state file %s: %w", s.Path, err)
+	}
+	if len(data) == 0 {
+		return trips, nil
+	}
+	if err := json.Unmarshal(data, &trips); err != nil {
+		return nil, fmt.Errorf("failed to parse guard state file %s: %w", s.Path, err)
+	}
+	return trips, nil
+}
+
+// Save writes trips to disk as JSON, overwriting whatever was there before.
+func (s *FileStore) Save(trips map[common.Address]*Trip) error {
+	data, err := json.Marshal(trips)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guard state: %w", err)
+	}
+	if err := os.WriteFile(s.Path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write guard state file %s: %w", s.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"sync"
+	"time"
+)
+
+// failureThreshold is the number of consecutive failures that trips a chain's circuit breaker.
+const failureThreshold = 3
+
+// openDuration is how long a tripped breaker refuses calls before allowing a single probe
+// through again.
+const openDuration = 30 * time.Second
+
+// breaker is a minimal per-chain circuit breaker: it opens after failureThreshold consecutive
+// failures and half-opens (allows one probe) after openDuration, so a chain with a dead RPC
+// cannot keep consuming dial attempts or stall callers iterating over all chains.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// newBreaker returns a breaker that starts closed.
+func newBreaker() *breaker {
+	return &breaker{}
+}
+
+// Allow reports whether a call should be attempted: true if the breaker is closed, or if it is
+// open but openDuration has elapsed and a probe should be let through.
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < failureThreshold {
+		return true
+	}
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return false
+	}
+	// Re-arm the open window so only this probe gets through until Success or Failure.
+	b.openUntil = now.Add(openDuration)
+	return true
+}
+
+// Success resets the breaker to closed.
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// Failure records a failed call, tripping the breaker open for openDuration once
+// failureThreshold consecutive failures have been seen.
+func (b *breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= failureThreshold {
+		b.openUntil = time.Now().Add(openDuration)
+	}
+}
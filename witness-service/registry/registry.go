@@ -0,0 +1,214 @@
+// Copyright (c) 2021 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package registry generalises the single-chain contract.NewAddressList(address, backend)
+// pattern into a Registry keyed by chain id, so a process can talk to the same AddressList
+// contract as deployed independently on several chains (e.g. Ethereum, Polygon, Base, Arbitrum)
+// without hard-coding any of them.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"go.uber.org/config"
+
+	"github.com/iotexproject/ioTube/witness-service/contract"
+)
+
+// ChainConfig describes a single chain's AddressList deployment.
+type ChainConfig struct {
+	ID          uint64 `json:"id" yaml:"id"`
+	RPC         string `json:"rpc" yaml:"rpc"`
+	AddressList string `json:"addressList" yaml:"addressList"`
+}
+
+// Config is the top-level registry configuration.
+type Config struct {
+	Chains []ChainConfig `json:"chains" yaml:"chains"`
+}
+
+// LoadConfig reads a Config from a YAML/JSON file, expanding $VAR/${VAR} references against the
+// process environment so operators can drop in new chains without recompiling.
+func LoadConfig(file string) (Config, error) {
+	yaml, err := config.NewYAML(config.File(file), config.Expand(os.LookupEnv))
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to load registry config %s: %w", file, err)
+	}
+	var cfg Config
+	if err := yaml.Get(config.Root).Populate(&cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to populate registry config %s: %w", file, err)
+	}
+	return cfg, nil
+}
+
+// OwnershipTransferred is an AddressList OwnershipTransferred event tagged with the chain it was
+// observed on, as delivered by Registry.WatchOwnershipTransferred.
+type OwnershipTransferred struct {
+	ChainID uint64
+	*contract.AddressListOwnershipTransferred
+}
+
+// chainEntry is the lazily-dialed state the Registry keeps for one configured chain.
+type chainEntry struct {
+	cfg     ChainConfig
+	breaker *breaker
+
+	mu      sync.Mutex
+	client  *ethclient.Client
+	binding *contract.AddressList
+}
+
+// Registry is a collection of AddressList bindings keyed by chain id, dialed on demand. A
+// circuit breaker guards each chain so a failing RPC only affects queries against that chain,
+// not the others.
+type Registry struct {
+	entries map[uint64]*chainEntry
+}
+
+// New builds a Registry from cfg. No RPC dial happens until a chain is first used, either via
+// ForChain or WatchOwnershipTransferred.
+func New(cfg Config) (*Registry, error) {
+	entries := make(map[uint64]*chainEntry, len(cfg.Chains))
+	for _, c := range cfg.Chains {
+		if _, ok := entries[c.ID]; ok {
+			return nil, fmt.Errorf("duplicate chain id %d in registry config", c.ID)
+		}
+		entries[c.ID] = &chainEntry{cfg: c, breaker: newBreaker()}
+	}
+	return &Registry{entries: entries}, nil
+}
+
+// ForChain returns the AddressList binding for chainID, dialing its RPC and binding the contract
+// the first time it is requested. A chain whose circuit breaker is open fails fast without
+// attempting to dial.
+func (r *Registry) ForChain(chainID uint64) (*contract.AddressList, error) {
+	entry, ok := r.entries[chainID]
+	if !ok {
+		return nil, fmt.Errorf("chain %d is not configured in the registry", chainID)
+	}
+	if !entry.breaker.Allow() {
+		return nil, fmt.Errorf("chain %d: circuit breaker open", chainID)
+	}
+	binding, err := entry.bind()
+	if err != nil {
+		entry.breaker.Failure()
+		return nil, err
+	}
+	entry.breaker.Success()
+	return binding, nil
+}
+
+// bind dials the chain's RPC and binds the AddressList contract the first time it is called,
+// reusing the result on subsequent calls.
+func (e *chainEntry) bind() (*contract.AddressList, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.binding != nil {
+		return e.binding, nil
+	}
+	client, err := ethclient.Dial(e.cfg.RPC)
+	if err != nil {
+		return nil, fmt.Errorf("chain %d: failed to dial %s: %w", e.cfg.ID, e.cfg.RPC, err)
+	}
+	binding, err := contract.NewAddressList(common.HexToAddress(e.cfg.AddressList), client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("chain %d: failed to bind AddressList at %s: %w", e.cfg.ID, e.cfg.AddressList, err)
+	}
+	e.client = client
+	e.binding = binding
+	return binding, nil
+}
+
+// WatchOwnershipTransferred subscribes to OwnershipTransferred on every configured chain and
+// fans the events into a single merged channel, each tagged with the ChainID it came from. A
+// chain whose RPC cannot be dialed or subscribed to trips its circuit breaker and is retried on
+// its own schedule instead of blocking delivery for the other chains.
+func (r *Registry) WatchOwnershipTransferred(ctx context.Context, retryInterval time.Duration) (<-chan *OwnershipTransferred, error) {
+	if len(r.entries) == 0 {
+		return nil, fmt.Errorf("registry has no configured chains")
+	}
+	merged := make(chan *OwnershipTransferred)
+	for _, entry := range r.entries {
+		go r.watchChain(ctx, entry, retryInterval, merged)
+	}
+	return merged, nil
+}
+
+// watchChain keeps a single chain's OwnershipTransferred subscription alive, retrying with
+// retryInterval backoff through the chain's circuit breaker whenever the dial or subscription
+// fails, without affecting any other chain's subscription.
+func (r *Registry) watchChain(ctx context.Context, entry *chainEntry, retryInterval time.Duration, merged chan<- *OwnershipTransferred) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if !entry.breaker.Allow() {
+			if !sleep(ctx, retryInterval) {
+				return
+			}
+			continue
+		}
+		if err := r.watchChainOnce(ctx, entry, merged); err != nil {
+			entry.breaker.Failure()
+			if !sleep(ctx, retryInterval) {
+				return
+			}
+			continue
+		}
+		entry.breaker.Success()
+	}
+}
+
+// watchChainOnce binds entry's chain if needed and forwards its OwnershipTransferred events to
+// merged until the subscription ends, ctx is cancelled, or an error occurs.
+func (r *Registry) watchChainOnce(ctx context.Context, entry *chainEntry, merged chan<- *OwnershipTransferred) error {
+	binding, err := entry.bind()
+	if err != nil {
+		return err
+	}
+	sink := make(chan *contract.AddressListOwnershipTransferred)
+	var sub event.Subscription
+	sub, err = binding.WatchOwnershipTransferred(nil, sink, nil, nil)
+	if err != nil {
+		return fmt.Errorf("chain %d: failed to watch OwnershipTransferred: %w", entry.cfg.ID, err)
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case ev := <-sink:
+			select {
+			case merged <- &OwnershipTransferred{ChainID: entry.cfg.ID, AddressListOwnershipTransferred: ev}:
+			case <-ctx.Done():
+				return nil
+			}
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sleep waits for d or ctx cancellation, reporting whether it returned because of the timer
+// (true) rather than cancellation (false).
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}